@@ -0,0 +1,154 @@
+// Package client connects to an ASnake server, either as a player or as a bot.
+//
+// A minimal bot:
+//
+//	cl, err := client.Dial("127.0.0.1:17530", client.ModeBot)
+//	if err != nil {
+//		panic(err)
+//	}
+//	defer cl.Close()
+//	if _, err := cl.WaitStart(nil); err != nil {
+//		panic(err)
+//	}
+//	err = cl.RunBot(func(obs game.Observation) string { return "right" })
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"ASnake/game"
+)
+
+type (
+	Client struct {
+		Conn     net.Conn
+		Mode     string
+		ClientId string
+		reader   *bufio.Reader
+	}
+)
+
+const (
+	// Receives a `game.GameState` on every change, answers with a direction at any time.
+	ModePlayer string = "Join"
+	// Receives a `game.Observation` every time its snake moved, answers with `<Tick> <direction>` before the deadline.
+	ModeBot string = "Bot"
+)
+
+var ErrRejected = errors.New("rejected by server")
+
+// Connect to the server at `addr` and join a pool in `mode`.
+func Dial(addr string, mode string) (*Client, error) {
+	tcp, err := net.ResolveTCPAddr("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTCP("tcp", nil, tcp)
+	if err != nil {
+		return nil, err
+	}
+
+	cl := &Client{Conn: conn, Mode: mode, reader: bufio.NewReader(conn)}
+
+	if _, err := conn.Write([]byte(mode + "\n")); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	msg, err := cl.readLine()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if msg != "Accept" {
+		_ = conn.Close()
+		return nil, errors.Join(ErrRejected, errors.New(msg))
+	}
+
+	return cl, nil
+}
+
+func (cl *Client) readLine() (string, error) {
+	msg, err := cl.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(msg, "\n", ""), nil
+}
+
+// Wait for the pool to start, `onWait` is called every time the server reports it is still waiting for players.
+func (cl *Client) WaitStart(onWait func()) (game.FirstUpdatePacket, error) {
+	update := game.FirstUpdatePacket{}
+
+	msg, err := cl.readLine()
+	if err != nil {
+		return update, err
+	}
+	for msg == "waiting" {
+		if onWait != nil {
+			onWait()
+		}
+		if msg, err = cl.readLine(); err != nil {
+			return update, err
+		}
+	}
+
+	if err := json.Unmarshal([]byte(msg), &update); err != nil {
+		return update, err
+	}
+	cl.ClientId = update.ClientId
+	return update, nil
+}
+
+// Read the next observation, only available in `ModeBot`.
+func (cl *Client) Observe() (game.Observation, error) {
+	obs := game.Observation{}
+
+	msg, err := cl.readLine()
+	if err != nil {
+		return obs, err
+	}
+	err = json.Unmarshal([]byte(msg), &obs)
+	return obs, err
+}
+
+// Send the direction to move in, `tick` is ignored in `ModePlayer`.
+func (cl *Client) Move(tick int, dir string) error {
+	msg := dir + "\n"
+	if cl.Mode == ModeBot {
+		msg = strconv.Itoa(tick) + " " + msg
+	}
+	_, err := cl.Conn.Write([]byte(msg))
+	return err
+}
+
+// Answer every observation with the direction returned by `decide` until the connection closes or the bot is game over.
+func (cl *Client) RunBot(decide func(obs game.Observation) string) error {
+	for {
+		obs, err := cl.Observe()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if obs.Players[cl.ClientId].IsGameOver {
+			return nil
+		}
+
+		if err := cl.Move(obs.Tick, decide(obs)); err != nil {
+			return err
+		}
+	}
+}
+
+func (cl *Client) Close() error {
+	return cl.Conn.Close()
+}
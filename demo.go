@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"ASnake/game"
+
+	"github.com/HandyGold75/GOLib/tui"
+)
+
+type (
+	// Plays a demo game behind the menu while the menu has not rendered for a while.
+	//
+	// The menu only rerenders on user input, so its output is routed through a pipe to detect activity.
+	menuDemo struct {
+		idle     time.Duration
+		out      *os.File
+		pipe     *os.File
+		mu       sync.Mutex
+		last     time.Time
+		stop     chan struct{}
+		done     chan struct{}
+		stopping bool
+	}
+)
+
+// Get a new bulky main menu that starts a demo game after being idle for `idle`.
+//
+// `md.Close` should be called once the menu has finished.
+func newMenuDemo(title string, idle time.Duration) (*tui.MainMenu, *menuDemo, error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	md := &menuDemo{
+		idle: idle,
+		out:  os.Stdout,
+		pipe: pw,
+		last: time.Now(),
+	}
+
+	os.Stdout = pw
+	mm := tui.NewMenuBulky(title)
+	os.Stdout = md.out
+
+	go md.forward(pr)
+	go md.watch()
+
+	return mm, md, nil
+}
+
+func (md *menuDemo) forward(pr *os.File) {
+	defer func() { _ = pr.Close() }()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := pr.Read(buf)
+		if err != nil {
+			return
+		}
+
+		md.mu.Lock()
+		md.stopDemo()
+		md.last = time.Now()
+		_, _ = md.out.Write(buf[:n])
+		md.mu.Unlock()
+	}
+}
+
+func (md *menuDemo) watch() {
+	for {
+		time.Sleep(time.Millisecond * 250)
+
+		md.mu.Lock()
+		if md.stopping {
+			md.mu.Unlock()
+			return
+		}
+		if md.stop == nil && time.Since(md.last) > md.idle {
+			md.startDemo()
+		}
+		md.mu.Unlock()
+	}
+}
+
+// Should be called with `md.mu` locked.
+func (md *menuDemo) startDemo() {
+	gm, err := game.NewGame(false)
+	if err != nil {
+		md.last = time.Now()
+		return
+	}
+
+	md.stop, md.done = make(chan struct{}), make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		gm.Demo(stop)
+	}(md.stop, md.done)
+}
+
+// Should be called with `md.mu` locked.
+func (md *menuDemo) stopDemo() {
+	if md.stop == nil {
+		return
+	}
+	close(md.stop)
+	<-md.done
+	md.stop, md.done = nil, nil
+}
+
+// Stop the demo if it is playing and release the menu output.
+func (md *menuDemo) Close() error {
+	md.mu.Lock()
+	md.stopDemo()
+	md.stopping = true
+	md.mu.Unlock()
+
+	return md.pipe.Close()
+}
@@ -0,0 +1,305 @@
+package game
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type (
+	// Layout of an arena loaded from a map file.
+	//
+	// Cords include the border, which is drawn following `WallMode` instead of the map.
+	Arena struct {
+		Name          string
+		Width, Height int
+		// Wall mode of the border, empty keeps the configured wall mode.
+		WallMode string
+		Walls    [][2]int
+		// Start cords of players in order, spawns are random when empty.
+		Spawns [][2]int
+		// Rectangles as `[x, y, width, height]` peas spawn in, peas spawn anywhere when empty.
+		PeaZones [][4]int
+		// Pairs of cells teleporting snakes to each other.
+		Portals [][2][2]int
+	}
+)
+
+const (
+	// Name of the arena without a map.
+	ArenaNone string = "none"
+	// Smallest width and height of a playable arena, including its border.
+	ArenaMinSize int = 5
+	// Most portal pairs an arena can have, one per letter of its text map.
+	ArenaMaxPortals int = 26
+)
+
+var (
+	ErrArenaUnknown = errors.New("unknown arena")
+	ErrArenaSize    = errors.New("arena should be at least 5x5")
+	ErrArenaChar    = errors.New("unknown map character")
+	ErrArenaPortal  = errors.New("portal should have exactly 2 cells")
+	ErrArenaPortals = errors.New("arena should have at most 26 portals")
+	ErrArenaOutside = errors.New("arena cell outside of the arena")
+	ErrArenaZone    = errors.New("pea zone should be at least 1x1")
+	ErrArenaWalls   = errors.New("unknown arena wall mode")
+
+	//go:embed arenas/*.txt
+	builtinArenas embed.FS
+)
+
+// Get the names of the arenas embedded in the binary, sorted.
+func BuiltinArenas() []string {
+	entries, _ := builtinArenas.ReadDir("arenas")
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Get a built-in arena by name or load a map file from `name` otherwise.
+func ResolveArena(name string) (*Arena, error) {
+	if data, err := builtinArenas.ReadFile(path.Join("arenas", name+".txt")); err == nil {
+		return ParseArena(name, data)
+	}
+	if _, err := os.Stat(name); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArenaUnknown, name)
+	}
+	return LoadArena(name)
+}
+
+// Load a map file, files ending in `.json` hold an `Arena` while all other files hold a text map.
+func LoadArena(path string) (*Arena, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if filepath.Ext(path) != ".json" {
+		return ParseArena(name, data)
+	}
+
+	arena := &Arena{Name: name}
+	if err := json.Unmarshal(data, arena); err != nil {
+		return nil, err
+	}
+	return arena, arena.validate()
+}
+
+// Save the arena as a map file, files ending in `.json` hold an `Arena` while all other files hold a text map.
+func (arena *Arena) Save(path string) error {
+	if filepath.Ext(path) != ".json" {
+		return os.WriteFile(path, []byte(arena.Text()), 0o644)
+	}
+
+	data, err := json.MarshalIndent(arena, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Parse a text map.
+//
+// Optional `key: value` lines for `name` and `walls` come first, followed by one line per row where every character is a cell:
+// `#` wall, `S` spawn, `+` pea zone, `a` to `z` portals linked to the other cell with the same letter and `.` or a space for empty cells.
+func ParseArena(name string, data []byte) (*Arena, error) {
+	arena := &Arena{Name: name}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for len(lines) > 0 {
+		key, value, ok := strings.Cut(lines[0], ": ")
+		if !ok || strings.ContainsAny(key, " #.") {
+			break
+		}
+		switch strings.ToLower(key) {
+		case "name":
+			arena.Name = value
+		case "walls":
+			arena.WallMode = value
+		}
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	portals := map[rune][][2]int{}
+	for y, line := range lines {
+		arena.Height = max(arena.Height, y+1)
+		for x, r := range []rune(line) {
+			arena.Width = max(arena.Width, x+1)
+			crd := [2]int{x, y}
+
+			switch {
+			case r == '#':
+				arena.Walls = append(arena.Walls, crd)
+			case r == 'S':
+				arena.Spawns = append(arena.Spawns, crd)
+			case r == '+':
+				arena.PeaZones = append(arena.PeaZones, [4]int{x, y, 1, 1})
+			case r >= 'a' && r <= 'z':
+				portals[r] = append(portals[r], crd)
+			case r == '.' || r == ' ':
+			default:
+				return nil, fmt.Errorf("%w: %q", ErrArenaChar, r)
+			}
+		}
+	}
+
+	for _, r := range slices.Sorted(maps.Keys(portals)) {
+		if len(portals[r]) != 2 {
+			return nil, fmt.Errorf("%w: %q", ErrArenaPortal, r)
+		}
+		arena.Portals = append(arena.Portals, [2][2]int{portals[r][0], portals[r][1]})
+	}
+
+	return arena, arena.validate()
+}
+
+func (arena *Arena) validate() error {
+	if arena.Width < ArenaMinSize || arena.Height < ArenaMinSize {
+		return ErrArenaSize
+	}
+	if arena.WallMode != "" && !slices.Contains(WallModes, arena.WallMode) {
+		return fmt.Errorf("%w: %v", ErrArenaWalls, arena.WallMode)
+	}
+	if len(arena.Portals) > ArenaMaxPortals {
+		return ErrArenaPortals
+	}
+
+	cells := slices.Concat(arena.Walls, arena.Spawns)
+	for _, portal := range arena.Portals {
+		cells = append(cells, portal[0], portal[1])
+	}
+	for _, zone := range arena.PeaZones {
+		if zone[2] < 1 || zone[3] < 1 {
+			return fmt.Errorf("%w: %v", ErrArenaZone, zone)
+		}
+		cells = append(cells, [2]int{zone[0], zone[1]}, [2]int{zone[0] + zone[2] - 1, zone[1] + zone[3] - 1})
+	}
+	for _, crd := range cells {
+		if crd[0] < 0 || crd[1] < 0 || crd[0] >= arena.Width || crd[1] >= arena.Height {
+			return fmt.Errorf("%w: %v", ErrArenaOutside, crd)
+		}
+	}
+	return nil
+}
+
+// Write the arena as a text map.
+func (arena *Arena) Text() string {
+	rows := make([][]rune, arena.Height)
+	for y := range rows {
+		rows[y] = []rune(strings.Repeat(".", arena.Width))
+	}
+	for _, zone := range arena.PeaZones {
+		for y := zone[1]; y < zone[1]+zone[3]; y++ {
+			for x := zone[0]; x < zone[0]+zone[2]; x++ {
+				rows[y][x] = '+'
+			}
+		}
+	}
+	for _, crd := range arena.Walls {
+		rows[crd[1]][crd[0]] = '#'
+	}
+	for _, crd := range arena.Spawns {
+		rows[crd[1]][crd[0]] = 'S'
+	}
+	for i, portal := range arena.Portals {
+		for _, crd := range portal {
+			rows[crd[1]][crd[0]] = 'a' + rune(i)
+		}
+	}
+
+	lines := []string{"name: " + arena.Name}
+	if arena.WallMode != "" {
+		lines = append(lines, "walls: "+arena.WallMode)
+	}
+	for _, row := range rows {
+		lines = append(lines, string(row))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Use `arena` for this game, resizing the board to it and clearing it, nil goes back to an empty arena of the current size.
+func (game *Game) LoadArena(arena *Arena) {
+	game.Arena = arena
+	game.portalLinks = map[[2]int][2]int{}
+	if arena == nil {
+		game.Board.Clear()
+		game.DrawBorder()
+		return
+	}
+
+	if arena.WallMode != "" {
+		game.Config.WallMode = arena.WallMode
+	}
+	for _, portal := range arena.Portals {
+		game.portalLinks[portal[0]] = portal[1]
+		game.portalLinks[portal[1]] = portal[0]
+	}
+
+	game.Board.Resize(arena.Width, arena.Height)
+	game.DrawBorder()
+}
+
+// Draw the walls and portals of the arena.
+func (game *Game) drawArena() {
+	if game.Arena == nil {
+		return
+	}
+	for _, crd := range game.Arena.Walls {
+		_ = game.Board.SetColRow(crd[0], crd[1], ObjWall)
+	}
+	for crd := range game.portalLinks {
+		if val, err := game.Board.GetColRow(crd[0], crd[1]); err == nil && val == ObjEmpty {
+			_ = game.Board.SetColRow(crd[0], crd[1], ObjPortal)
+		}
+	}
+}
+
+// Get the start cords of the `i`th player, following the spawns of the arena when it has any.
+func (game *Game) StartCrd(i int) [2]int {
+	if game.Arena != nil && len(game.Arena.Spawns) > 0 {
+		return game.Arena.Spawns[i%len(game.Arena.Spawns)]
+	}
+
+	startY := int(game.Board.CurY / 2)
+	if i%2 == 0 {
+		startY += i
+	} else {
+		startY -= (i + 1)
+	}
+	return [2]int{int(game.Board.CurX / 2), startY}
+}
+
+// Get a random cell for a new pea, inside a pea zone when the arena has any.
+func (game *Game) peaCrd() [2]int {
+	if game.Arena == nil || len(game.Arena.PeaZones) == 0 {
+		return [2]int{rand.IntN(game.Board.CurX-1) + 1, rand.IntN(game.Board.CurY-1) + 1}
+	}
+
+	total := 0
+	for _, zone := range game.Arena.PeaZones {
+		total += zone[2] * zone[3]
+	}
+	pick := rand.IntN(max(1, total))
+	for _, zone := range game.Arena.PeaZones {
+		if pick < zone[2]*zone[3] {
+			return [2]int{zone[0] + pick%zone[2], zone[1] + pick/zone[2]}
+		}
+		pick -= zone[2] * zone[3]
+	}
+	return [2]int{}
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"errors"
+)
+
+type (
+	// Grid of objects the game is played on, the simulation only reads and writes the board and never the screen drawing it.
+	Board struct {
+		Rows [][]uint8
+		// Index of the last column and row.
+		CurX, CurY int
+	}
+)
+
+var (
+	ErrBoardX = errors.New("x is outside of the board")
+	ErrBoardY = errors.New("y is outside of the board")
+)
+
+// Get an empty board of `x` columns and `y` rows.
+func NewBoard(x, y int) *Board {
+	board := &Board{}
+	board.Resize(x, y)
+	return board
+}
+
+// Resize the board to `x` columns and `y` rows, clearing all cells.
+func (board *Board) Resize(x, y int) {
+	board.CurX, board.CurY = x-1, y-1
+	board.Rows = make([][]uint8, y)
+	for i := range board.Rows {
+		board.Rows[i] = make([]uint8, x)
+	}
+}
+
+func (board *Board) SetRow(y int, state uint8) error {
+	if y > board.CurY || y < 0 {
+		return ErrBoardY
+	}
+
+	for x := range board.Rows[y] {
+		board.Rows[y][x] = state
+	}
+	return nil
+}
+
+func (board *Board) SetCol(x int, state uint8) error {
+	if x > board.CurX || x < 0 {
+		return ErrBoardX
+	}
+
+	for y := range board.Rows {
+		board.Rows[y][x] = state
+	}
+	return nil
+}
+
+func (board *Board) SetColRow(x, y int, state uint8) error {
+	if y > board.CurY || y < 0 {
+		return ErrBoardY
+	}
+	if x > board.CurX || x < 0 {
+		return ErrBoardX
+	}
+
+	board.Rows[y][x] = state
+	return nil
+}
+
+func (board *Board) GetColRow(x, y int) (uint8, error) {
+	if y > board.CurY || y < 0 {
+		return 0, ErrBoardY
+	}
+	if x > board.CurX || x < 0 {
+		return 0, ErrBoardX
+	}
+
+	return board.Rows[y][x], nil
+}
+
+func (board *Board) Clear() {
+	for _, row := range board.Rows {
+		clear(row)
+	}
+}
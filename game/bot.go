@@ -0,0 +1,262 @@
+package game
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	BotLevel int
+)
+
+const (
+	// Walks straight and turns at random, even into walls and snakes.
+	BotRandom BotLevel = iota
+	// Walks at random but never moves into a snake.
+	BotEasy
+	// Moves greedily towards the nearest pea while never moving into a snake.
+	BotMedium
+	// Follows the shortest path to the nearest pea and avoids moves that trap itself.
+	BotHard
+)
+
+var (
+	ErrBotLevelUnknown = errors.New("unknown bot level")
+	ErrNoSpawnSpace    = errors.New("no space left for new player")
+
+	// Names of all bot levels, indexed by `BotLevel`.
+	BotLevels = []string{"Random", "Easy", "Medium", "Hard"}
+
+	// All directions in the order they are tried.
+	Dirs = []string{"up", "right", "down", "left"}
+)
+
+// Parse a bot level by name as listed in `BotLevels`, case insensitive.
+func ParseBotLevel(name string) (BotLevel, error) {
+	i := slices.IndexFunc(BotLevels, func(lvl string) bool { return strings.EqualFold(lvl, name) })
+	if i == -1 {
+		return BotRandom, ErrBotLevelUnknown
+	}
+	return BotLevel(i), nil
+}
+
+func (lvl BotLevel) String() string {
+	if int(lvl) < 0 || int(lvl) >= len(BotLevels) {
+		return "Unknown"
+	}
+	return BotLevels[lvl]
+}
+
+// Get the direction opposite of `dir`.
+func OppositeDir(dir string) string {
+	switch dir {
+	case "up":
+		return "down"
+	case "right":
+		return "left"
+	case "down":
+		return "up"
+	case "left":
+		return "right"
+	}
+	return ""
+}
+
+// Find a free spot for a new player with at least 2 empty cells in every direction, preferring the spawns of the arena.
+func (game *Game) FreeSpawn() ([2]int, error) {
+	if game.Arena != nil {
+		for _, i := range rand.Perm(len(game.Arena.Spawns)) {
+			cord := game.Arena.Spawns[i]
+			if val, err := game.Board.GetColRow(cord[0], cord[1]); err == nil && val == ObjEmpty && game.freeArea(cord, 9) >= 9 {
+				return cord, nil
+			}
+		}
+	}
+
+	for i := 1; i < 100; i++ {
+		cord := [2]int{rand.IntN(game.Board.CurX-1) + 1, rand.IntN(game.Board.CurY-1) + 1}
+
+		valid := true
+		for y := -2; y < 3 && valid; y++ {
+			for x := -2; x < 3; x++ {
+				val, _ := game.Board.GetColRow(cord[0]+x, cord[1]+y)
+				if val != ObjEmpty {
+					valid = false
+					break
+				}
+			}
+		}
+		if valid {
+			return cord, nil
+		}
+	}
+	return [2]int{}, ErrNoSpawnSpace
+}
+
+// Add a computer controlled player at a free spot.
+//
+// Returns the id of the new bot.
+func (game *Game) AddBot(level BotLevel) (string, error) {
+	cord, err := game.FreeSpawn()
+	if err != nil {
+		return "", err
+	}
+
+	game.botCount++
+	id := "bot" + strconv.Itoa(game.botCount)
+	game.Bots[id] = level
+	game.State.Players[id] = Player{
+		Crd: cord,
+		Dir: "right", CurDir: "right",
+		TailCrds: [][2]int{},
+		Color:    game.NextColor(),
+	}
+	_ = game.Board.SetColRow(cord[0], cord[1], ObjBot)
+
+	return id, nil
+}
+
+// Steer all bots that are still alive, call it right before `UpdatePlayers`.
+func (game *Game) UpdateBots() {
+	for id, level := range game.Bots {
+		playerState, ok := game.State.Players[id]
+		if !ok || playerState.IsGameOver {
+			continue
+		}
+		playerState.Dir = game.BotDir(id, level)
+		game.State.Players[id] = playerState
+	}
+}
+
+// Get the direction the bot `id` wants to move in at `level`.
+func (game *Game) BotDir(id string, level BotLevel) string {
+	playerState := game.State.Players[id]
+
+	dirs := slices.DeleteFunc(slices.Clone(Dirs), func(dir string) bool { return dir == OppositeDir(playerState.CurDir) })
+	safe := slices.DeleteFunc(slices.Clone(dirs), func(dir string) bool { return !game.isFree(game.NextCrd(playerState.Crd, dir)) })
+
+	switch level {
+	case BotRandom:
+		if rand.IntN(5) == 0 {
+			return dirs[rand.IntN(len(dirs))]
+		}
+		return playerState.CurDir
+
+	case BotEasy:
+		if len(safe) == 0 {
+			return playerState.CurDir
+		}
+		if slices.Contains(safe, playerState.CurDir) && rand.IntN(5) != 0 {
+			return playerState.CurDir
+		}
+		return safe[rand.IntN(len(safe))]
+
+	case BotMedium:
+		if len(safe) == 0 {
+			return playerState.CurDir
+		}
+		best, bestDist := safe[rand.IntN(len(safe))], -1
+		for _, dir := range safe {
+			crd := game.NextCrd(playerState.Crd, dir)
+			for _, pea := range game.State.PeaCrds {
+				dist := abs(pea[0]-crd[0]) + abs(pea[1]-crd[1])
+				if bestDist == -1 || dist < bestDist {
+					best, bestDist = dir, dist
+				}
+			}
+		}
+		return best
+
+	default:
+		if len(safe) == 0 {
+			return playerState.CurDir
+		}
+		roomy := slices.DeleteFunc(slices.Clone(safe), func(dir string) bool {
+			return game.freeArea(game.NextCrd(playerState.Crd, dir), len(playerState.TailCrds)+1) <= len(playerState.TailCrds)
+		})
+		if dir, ok := game.pathToPea(playerState.Crd, roomy); ok {
+			return dir
+		}
+
+		best, bestArea := safe[0], -1
+		for _, dir := range safe {
+			if area := game.freeArea(game.NextCrd(playerState.Crd, dir), game.Board.CurX*game.Board.CurY); area > bestArea {
+				best, bestArea = dir, area
+			}
+		}
+		return best
+	}
+}
+
+// Check if a snake can move onto `crd` without dying.
+func (game *Game) isFree(crd [2]int) bool {
+	val, err := game.Board.GetColRow(crd[0], crd[1])
+	return err == nil && !IsDeadly(val)
+}
+
+// Find the first step of the shortest path from `from` to any pea, only starting in one of `dirs`.
+func (game *Game) pathToPea(from [2]int, dirs []string) (string, bool) {
+	firstDir := map[[2]int]string{}
+	queue := [][2]int{}
+	for _, dir := range dirs {
+		crd := game.NextCrd(from, dir)
+		if _, ok := firstDir[crd]; ok {
+			continue
+		}
+		firstDir[crd] = dir
+		queue = append(queue, crd)
+	}
+
+	for len(queue) > 0 {
+		crd := queue[0]
+		queue = queue[1:]
+
+		if val, _ := game.Board.GetColRow(crd[0], crd[1]); val == ObjPea {
+			return firstDir[crd], true
+		}
+
+		for _, dir := range Dirs {
+			next := game.NextCrd(crd, dir)
+			if _, ok := firstDir[next]; ok || next == from || !game.isFree(next) {
+				continue
+			}
+			firstDir[next] = firstDir[crd]
+			queue = append(queue, next)
+		}
+	}
+	return "", false
+}
+
+// Count free cells reachable from `from`, stops counting at `limit`.
+func (game *Game) freeArea(from [2]int, limit int) int {
+	if !game.isFree(from) {
+		return 0
+	}
+
+	seen := map[[2]int]bool{from: true}
+	queue := [][2]int{from}
+	for len(queue) > 0 && len(seen) < limit {
+		crd := queue[0]
+		queue = queue[1:]
+
+		for _, dir := range Dirs {
+			next := game.NextCrd(crd, dir)
+			if seen[next] || !game.isFree(next) {
+				continue
+			}
+			seen[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return len(seen)
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
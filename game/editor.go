@@ -0,0 +1,431 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ASnake/screen"
+
+	"golang.org/x/term"
+)
+
+type (
+	// Paints arenas in the terminal and saves them as map files.
+	Editor struct {
+		Path   string
+		Screen *screen.Screen
+		theme  *Theme
+		render string
+		// Rows of the map as characters of the text map format, including the outer ring.
+		grid           [][]rune
+		name, wallMode string
+		cursor         [2]int
+		brush          rune
+		// Snapshot of the map drawn by the game, nil while editing.
+		preview                            *screen.Screen
+		status                             string
+		drawing, dirty, quitting, stopping bool
+	}
+)
+
+const (
+	editorCursor uint8 = iota + ObjSnakeHead + PlayerColorSlots
+	editorSpawn
+	editorZone
+	// Portal `a` is drawn as `editorPortal`, `b` as `editorPortal + 1` and so on.
+	editorPortal
+)
+
+var (
+	ErrPortalsFull = errors.New("all portal letters are in use")
+
+	// Characters painted by the brushes in the order of their keys, `a` paints the next free portal letter.
+	editorBrushes = []rune{'#', 'S', '+', 'a', '.'}
+	// Wall modes cycled through by the editor, empty keeps the configured wall mode.
+	editorWallModes = append([]string{""}, WallModes...)
+)
+
+// Get a new editor for the map file at `path`, drawn with the colors of `theme` in render mode `render`.
+//
+// Built-in arena names open a copy of the arena saved as `<name>.txt`, any other missing file starts an empty map sized to the terminal.
+func NewEditor(path string, theme *Theme, render string) (*Editor, error) {
+	if !slices.Contains(RenderModes, render) {
+		return nil, fmt.Errorf("%w: %v", ErrRenderUnknown, render)
+	}
+	// Half blocks have no room for portal letters, the editor draws them as blocks.
+	if render == RenderHalf {
+		render = RenderBlocks
+	}
+	glyphs := renderGlyphs[render]
+	ed := &Editor{Path: path, theme: theme, render: render, brush: editorBrushes[0]}
+
+	if _, err := os.Stat(path); err == nil {
+		arena, err := LoadArena(path)
+		if err != nil {
+			return nil, err
+		}
+		ed.load(arena)
+	} else if slices.Contains(BuiltinArenas(), path) {
+		arena, err := ResolveArena(path)
+		if err != nil {
+			return nil, err
+		}
+		ed.Path = path + ".txt"
+		ed.load(arena)
+	} else {
+		termX, termY, err := term.GetSize(int(os.Stdin.Fd()))
+		if err != nil {
+			return nil, err
+		}
+		width, height := max(5, termX/2), max(5, termY-1)
+		ed.name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		ed.grid = make([][]rune, height)
+		for y := range ed.grid {
+			ed.grid[y] = []rune(strings.Repeat(".", width))
+		}
+	}
+
+	depth := screen.DetectColorDepth()
+	bg, _ := screen.Background(theme.Background, depth)
+	cell := func(color, glyph string) []byte {
+		if render == RenderASCII {
+			return []byte(glyph)
+		}
+		code, _ := screen.Color(color, depth)
+		return []byte(bg + code + glyph + Reset)
+	}
+
+	cursor, spawn, zone, portal := "▒▒", "██", "··", "░"
+	if render == RenderASCII {
+		cursor, spawn, zone, portal = "[]", "SS", "++", ":"
+	}
+	charMap := map[uint8][]byte{
+		ObjEmpty:     cell("", glyphs.Empty),
+		ObjWall:      cell(theme.Wall, glyphs.Wall),
+		ObjPortal:    cell(theme.Portal, glyphs.Portal),
+		editorCursor: cell(theme.Warning, cursor),
+		editorSpawn:  cell(theme.Players[0], spawn),
+		editorZone:   cell(theme.Pea, zone),
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		charMap[editorPortal+uint8(r-'a')] = cell(theme.Portal, string(r)+portal)
+	}
+	scr, err := screen.NewScreen(len(ed.grid[0]), len(ed.grid), true, charMap)
+	if err != nil {
+		return nil, err
+	}
+	ed.Screen = scr
+
+	return ed, nil
+}
+
+// Replace the map being edited with `arena`.
+func (ed *Editor) load(arena *Arena) {
+	lines := strings.Split(strings.TrimSuffix(arena.Text(), "\n"), "\n")
+	ed.grid = [][]rune{}
+	for _, line := range lines[len(lines)-arena.Height:] {
+		ed.grid = append(ed.grid, []rune(line))
+	}
+	ed.name, ed.wallMode = arena.Name, arena.WallMode
+	ed.cursor = [2]int{min(ed.cursor[0], arena.Width-1), min(ed.cursor[1], arena.Height-1)}
+	ed.dirty, ed.preview = false, nil
+}
+
+// Get the map being edited as an arena, returns an error if it is not a valid map.
+func (ed *Editor) Arena() (*Arena, error) {
+	lines := []string{"name: " + ed.name}
+	if ed.wallMode != "" {
+		lines = append(lines, "walls: "+ed.wallMode)
+	}
+	for _, row := range ed.grid {
+		lines = append(lines, string(row))
+	}
+	return ParseArena(ed.name, []byte(strings.Join(lines, "\n")))
+}
+
+// Save the map to `ed.Path`, in the format matching its extension.
+func (ed *Editor) Save() error {
+	arena, err := ed.Arena()
+	if err != nil {
+		return err
+	}
+	if err := arena.Save(ed.Path); err != nil {
+		return err
+	}
+	ed.dirty = false
+	return nil
+}
+
+// Load the map from `ed.Path` again, dropping all unsaved changes.
+func (ed *Editor) Reload() error {
+	arena, err := LoadArena(ed.Path)
+	if err != nil {
+		return err
+	}
+	ed.load(arena)
+	if ed.Screen.CurX != arena.Width-1 || ed.Screen.CurY != arena.Height-1 {
+		fmt.Print("\033[2J")
+	}
+	return nil
+}
+
+// Get a snapshot of the map as the game draws it, with players on every spawn and a few peas.
+func (ed *Editor) Preview() (*screen.Screen, error) {
+	arena, err := ed.Arena()
+	if err != nil {
+		return nil, err
+	}
+	gm, err := NewGame(true)
+	if err != nil {
+		return nil, err
+	}
+	gm.Render = ed.render
+	gm.ApplyTheme(ed.theme)
+
+	gm.LoadArena(arena)
+	for _, crd := range arena.Spawns {
+		_ = gm.Board.SetColRow(crd[0], crd[1], ObjPlayer)
+	}
+	for range max(gm.Config.PeaSpawnLimit, len(arena.PeaZones)/8) {
+		gm.SpawnPea()
+	}
+	gm.Screen.Show(gm.Board.Rows)
+	return gm.Screen, nil
+}
+
+// Get the next portal letter, finishing a pair before starting a new one.
+func (ed *Editor) portalLetter() (rune, error) {
+	counts := map[rune]int{}
+	for _, row := range ed.grid {
+		for _, r := range row {
+			counts[r]++
+		}
+	}
+
+	for r := 'a'; r <= 'z'; r++ {
+		if counts[r] == 1 {
+			return r, nil
+		}
+	}
+	for r := 'a'; r <= 'z'; r++ {
+		if counts[r] == 0 {
+			return r, nil
+		}
+	}
+	return 0, ErrPortalsFull
+}
+
+// Paint the cell under the cursor with the current brush.
+func (ed *Editor) paint() error {
+	r := ed.brush
+	if r == 'a' {
+		if cur := ed.grid[ed.cursor[1]][ed.cursor[0]]; cur >= 'a' && cur <= 'z' {
+			return nil
+		}
+		letter, err := ed.portalLetter()
+		if err != nil {
+			return err
+		}
+		r = letter
+	}
+
+	ed.grid[ed.cursor[1]][ed.cursor[0]] = r
+	ed.dirty = true
+	return nil
+}
+
+// Move the cursor by `dx` and `dy`, painting the new cell while drawing.
+func (ed *Editor) move(dx, dy int) error {
+	ed.cursor[0] = min(max(0, ed.cursor[0]+dx), len(ed.grid[0])-1)
+	ed.cursor[1] = min(max(0, ed.cursor[1]+dy), len(ed.grid)-1)
+	if ed.drawing {
+		return ed.paint()
+	}
+	return nil
+}
+
+func (ed *Editor) HandleInput(in []byte) error {
+	key := KeyName(in)
+	quitting := ed.quitting
+	ed.quitting, ed.status = false, ""
+
+	switch key {
+	case "q", "ESC", "CTRL_C", "CTRL_D":
+		if ed.dirty && !quitting {
+			ed.quitting = true
+			ed.status = "Unsaved changes, press " + key + " again to quit"
+			return nil
+		}
+		ed.stopping = true
+		return nil
+	case "CTRL_S":
+		if err := ed.Save(); err != nil {
+			return err
+		}
+		ed.status = "Saved " + ed.Path
+		return nil
+	case "v":
+		if ed.preview != nil {
+			ed.preview = nil
+			return nil
+		}
+		preview, err := ed.Preview()
+		if err != nil {
+			return err
+		}
+		ed.preview = preview
+		return nil
+	}
+	if ed.preview != nil {
+		return nil
+	}
+
+	switch key {
+	case "UP":
+		return ed.move(0, -1)
+	case "RIGHT":
+		return ed.move(1, 0)
+	case "DOWN":
+		return ed.move(0, 1)
+	case "LEFT":
+		return ed.move(-1, 0)
+	case "SPACE":
+		return ed.paint()
+	case "ENTER":
+		ed.drawing = !ed.drawing
+		if ed.drawing {
+			return ed.paint()
+		}
+	case "x", "BACKSPACE":
+		ed.grid[ed.cursor[1]][ed.cursor[0]] = '.'
+		ed.dirty = true
+	case "1", "2", "3", "4", "5":
+		i, _ := strconv.Atoi(key)
+		ed.brush = editorBrushes[i-1]
+	case "m":
+		i := slices.Index(editorWallModes, ed.wallMode)
+		ed.wallMode = editorWallModes[(i+1)%len(editorWallModes)]
+		ed.dirty = true
+	case "CTRL_R":
+		if err := ed.Reload(); err != nil {
+			return err
+		}
+		ed.status = "Reloaded " + ed.Path
+	}
+	return nil
+}
+
+// Draw the map with the cursor, or the preview while previewing, followed by the status line.
+func (ed *Editor) Draw() error {
+	if ed.preview != nil {
+		ed.preview.Follow(ed.cursor[0], ed.cursor[1])
+		if err := ed.preview.Draw(); err != nil {
+			return err
+		}
+		ed.statusLine("Preview   v edit   ^S save   q quit")
+		return nil
+	}
+
+	board := NewBoard(len(ed.grid[0]), len(ed.grid))
+	drawEdges(board, ed.wallMode)
+	for y, row := range ed.grid {
+		for x, r := range row {
+			switch {
+			case r == '#':
+				_ = board.SetColRow(x, y, ObjWall)
+			case r == 'S':
+				_ = board.SetColRow(x, y, editorSpawn)
+			case r == '+':
+				_ = board.SetColRow(x, y, editorZone)
+			case r >= 'a' && r <= 'z':
+				_ = board.SetColRow(x, y, editorPortal+uint8(r-'a'))
+			}
+		}
+	}
+	_ = board.SetColRow(ed.cursor[0], ed.cursor[1], editorCursor)
+
+	ed.Screen.Show(board.Rows)
+	ed.Screen.Follow(ed.cursor[0], ed.cursor[1])
+	if err := ed.Screen.Draw(); err != nil {
+		return err
+	}
+
+	walls := ed.wallMode
+	if walls == "" {
+		walls = "default"
+	}
+	pen := "up"
+	if ed.drawing {
+		pen = "down"
+	}
+	msg := fmt.Sprintf("%v   Brush: %v   Pen: %v   Walls: %v   Cell: %vx %vy   ",
+		ed.Path, brushName(ed.brush), pen, walls, ed.cursor[0], ed.cursor[1])
+	if ed.dirty {
+		msg = "*" + msg
+	}
+	if ed.status != "" {
+		msg += ed.status
+	} else {
+		msg += "1-5 brush   space paint   enter pen   x erase   m walls   v preview   ^S save   ^R reload   q quit"
+	}
+	ed.statusLine(msg)
+	return nil
+}
+
+// Draw `msg` below the map, cut off at the width of the map.
+func (ed *Editor) statusLine(msg string) {
+	viewX, _ := ed.Screen.ViewSize()
+	width := max(10, viewX*2)
+	if len([]rune(msg)) > width {
+		msg = string([]rune(msg)[:width-3]) + "..."
+	}
+	fmt.Print("\033[2K\r" + msg)
+}
+
+// Get the name of the brush painting `r`.
+func brushName(r rune) string {
+	switch r {
+	case '#':
+		return "Wall"
+	case 'S':
+		return "Spawn"
+	case '+':
+		return "Pea zone"
+	case 'a':
+		return "Portal"
+	}
+	return "Erase"
+}
+
+// Edit the map until quit, errors of actions are shown on the status line.
+func (ed *Editor) Run() error {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("stdin/ stdout should be a terminal")
+	}
+
+	terminal, err := screen.EnterFullscreen()
+	if err != nil {
+		return err
+	}
+	defer terminal.Restore()
+
+	for !ed.stopping {
+		if err := ed.Draw(); err != nil {
+			return err
+		}
+
+		in := make([]byte, 3)
+		if _, err := os.Stdin.Read(in); err != nil {
+			return err
+		}
+		if err := ed.HandleInput(in); err != nil {
+			ed.status = err.Error()
+		}
+	}
+	return nil
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand/v2"
+	"maps"
 	"net"
 	"os"
 	"slices"
@@ -19,47 +19,82 @@ import (
 )
 
 type (
-	keyBinds struct {
-		ESC, P,
-		CTRL_C, CTRL_D, Q,
-		W, D, S, A, K, L, J, H, UP, RIGHT, DOWN, LEFT []byte
-	}
-
 	Player struct {
 		Crd         [2]int
 		Dir, CurDir string
 		TailCrds    [][2]int
 		IsGameOver  bool
+		KilledBy    string
+		Score       Score
+		Effects     map[string]int
+		// Moves per second following the speed curve.
+		Speed int
+		// Body has decayed and is no longer on the board.
+		Decayed bool
+		// Index into the player colors of the theme, assigned when the player joins.
+		Color int
 	}
 
 	GameConfig struct {
-		LockFPSToTPS                                                           bool
+		LockFPSToTPS, ShorterDies, Pickups                                     bool
 		Connection                                                             net.Conn
 		ClientId                                                               string
+		LocalPlayers                                                           int
 		TargetTPS, TargetFPS                                                   int
 		PlayerSpeed, PeaSpawnDelay, PeaSpawnLimit, PeaStartCount, PlusOneDelay int
+		WallMode, DeadBodies, SpeedCurve                                       string
+		DecayDelay, SpeedStep, SpeedCap                                        int
+		// What happens when the terminal is resized, one of `ResizePolicies`.
+		ResizePolicy string
 	}
 	GameState struct {
 		Players       map[string]Player
 		PeaCrds       [][2]int
+		Pickups       []PlacedPickup
 		PlusOneActive bool
 		TpsTracker    int
 	}
 	Game struct {
-		KeyBinds   keyBinds
-		Config     GameConfig
-		State      GameState
+		KeyBinds  KeyBinds
+		Bots      map[string]BotLevel
+		Arena     *Arena
+		Autopilot bool
+		Config    GameConfig
+		State     GameState
+		Board     *Board
+		// Draws the board, only `Board` is used by the game logic.
 		Screen     *screen.Screen
+		Theme      *Theme
+		ColorDepth screen.ColorDepth
+		// One of `RenderModes`, switched with `SetRender`.
+		Render     string
 		StartTime  time.Time
 		fpsTracker int
-		stopping   bool
-		paused     bool
+		decays     map[string]int
+		moves      int
+		// Bots added so far, only ever increases so bot ids are never reused.
+		botCount int
+		progress map[string]int
+		// Both directions of every portal inside the arena.
+		portalLinks map[[2]int][2]int
+		// Snakes in the color of their player, drawn over their logical objects on the board.
+		entities *screen.Layer
+		// Text drawn over the view like "Paused", kept out of the board.
+		hud *screen.Layer
+		// Size changes of the terminal while playing, see `handleResize`.
+		resizes  <-chan struct{}
+		stopping bool
+		paused   bool
+		// Paused by `ResizePause` until the board fits the terminal again.
+		resizePaused bool
 	}
 
 	FirstUpdatePacket struct {
 		ClientId   string
 		StartTime  time.Time
 		MaxX, MaxY int
+		WallMode   string
+		Arena      *Arena
 		State      GameState
 	}
 )
@@ -83,7 +118,70 @@ const (
 	ObjPlusOne
 	ObjWarning
 	ObjPea
-	ObjPlayer
+	ObjPortal
+	ObjSpeed
+	ObjSlow
+	ObjGhost
+	ObjShrink
+	ObjMagnet
+	ObjDouble
+	// First object free for pickups registered with `RegisterPickup`.
+	ObjCustom
+)
+
+const (
+	ObjPlayer uint8 = iota + ObjCustom + 8
+	ObjPlayerTwo
+	ObjPlayerThree
+	ObjBot
+)
+
+const (
+	// Objects reserved for each kind of snake cell, a player with color `c` is drawn with `ObjSnakeOwn + c` and so on.
+	PlayerColorSlots uint8 = 16
+)
+
+const (
+	// Bodies of players controlled from this terminal.
+	ObjSnakeOwn uint8 = ObjBot + 1 + iota*PlayerColorSlots
+	// Bodies of all other players.
+	ObjSnakeOther
+	// Heads of all players.
+	ObjSnakeHead
+)
+
+const (
+	// Every edge is a portal to the opposite edge.
+	WallsWrap string = "wrap"
+	// Every edge is a wall.
+	WallsSolid string = "solid"
+	// Left and right edges are portals, top and bottom edges are walls.
+	WallsHorizontal string = "horizontal"
+	// Top and bottom edges are portals, left and right edges are walls.
+	WallsVertical string = "vertical"
+
+	// Bodies of dead snakes stay on the board.
+	BodiesStay string = "stay"
+	// Bodies of dead snakes turn into peas after the decay delay.
+	BodiesPeas string = "peas"
+	// Bodies of dead snakes disappear after the decay delay.
+	BodiesFade string = "fade"
+
+	// Terminals smaller than the board show the part around your snake.
+	ResizeClip string = "clip"
+	// Like `ResizeClip`, boards smaller than the terminal are centered.
+	ResizeLetterbox string = "letterbox"
+	// Like `ResizeClip`, but single player games pause while the board does not fit until resized or unpaused.
+	ResizePause string = "pause"
+)
+
+var (
+	// All wall modes in the order they are shown to the user.
+	WallModes = []string{WallsWrap, WallsSolid, WallsHorizontal, WallsVertical}
+	// All dead body modes in the order they are shown to the user.
+	BodyModes = []string{BodiesStay, BodiesPeas, BodiesFade}
+	// All resize policies in the order they are shown to the user.
+	ResizePolicies = []string{ResizeClip, ResizeLetterbox, ResizePause}
 )
 
 func NewGame(headless bool) (*Game, error) {
@@ -91,34 +189,26 @@ func NewGame(headless bool) (*Game, error) {
 	if headless {
 		maxX, maxY, forceMax = 50, 50, true
 	}
-	scr, err := screen.NewScreen(maxX, maxY, forceMax, map[uint8][]byte{
-		ObjEmpty:   []byte("  "),
-		ObjWall:    []byte(Black + "██" + Reset),
-		ObjPlusOne: []byte(Green + "██" + Reset),
-		ObjWarning: []byte(Red + "██" + Reset),
-		ObjPea:     []byte(Yellow + "██" + Reset),
-		ObjPlayer:  []byte(White + "██" + Reset),
-	})
+	theme, err := ResolveTheme(ThemeDefault)
 	if err != nil {
 		return &Game{}, err
 	}
-
-	_ = scr.SetCol(0, ObjWall)
-	_ = scr.SetRow(0, ObjWall)
-	_ = scr.SetCol(scr.CurX, ObjWall)
-	_ = scr.SetRow(scr.CurY, ObjWall)
+	scr, err := screen.NewScreen(maxX, maxY, forceMax, map[uint8][]byte{})
+	if err != nil {
+		return &Game{}, err
+	}
+	scr.MinimapRank = minimapRank
+	board := NewBoard(scr.CurX+1, scr.CurY+1)
+	scr.Show(board.Rows)
 
 	game := &Game{
-		KeyBinds: keyBinds{
-			ESC: []byte{27, 0, 0}, P: []byte{112, 0, 0},
-			CTRL_C: []byte{3, 0, 0}, CTRL_D: []byte{4, 0, 0}, Q: []byte{113, 0, 0},
-			W: []byte{119, 0, 0}, D: []byte{100, 0, 0}, S: []byte{115, 0, 0}, A: []byte{97, 0, 0},
-			K: []byte{107, 0, 0}, L: []byte{108, 0, 0}, J: []byte{106, 0, 0}, H: []byte{104, 0, 0},
-			UP: []byte{27, 91, 65}, RIGHT: []byte{27, 91, 67}, DOWN: []byte{27, 91, 66}, LEFT: []byte{27, 91, 68},
-		},
+		KeyBinds:  DefaultKeyBinds(),
+		Bots:      map[string]BotLevel{},
+		Autopilot: false,
 		Config: GameConfig{
 			LockFPSToTPS:  false,
 			ClientId:      "0",
+			LocalPlayers:  1,
 			TargetTPS:     30,
 			TargetFPS:     60,
 			PlayerSpeed:   5,
@@ -126,128 +216,301 @@ func NewGame(headless bool) (*Game, error) {
 			PeaSpawnLimit: 4,
 			PeaStartCount: 2,
 			PlusOneDelay:  1,
+			WallMode:      WallsWrap,
+			DeadBodies:    BodiesStay,
+			DecayDelay:    3,
+			SpeedCurve:    SpeedFixed,
+			SpeedStep:     5,
+			SpeedCap:      15,
+			ResizePolicy:  ResizeClip,
 		},
 		State: GameState{
 			Players:       map[string]Player{},
 			PeaCrds:       [][2]int{},
+			Pickups:       []PlacedPickup{},
 			PlusOneActive: false,
 			TpsTracker:    0,
 		},
-		Screen:     scr,
-		StartTime:  time.Now(),
-		fpsTracker: 0,
-		stopping:   false,
-		paused:     false,
+		Board:       board,
+		Screen:      scr,
+		ColorDepth:  screen.DetectColorDepth(),
+		Render:      DefaultRender(),
+		StartTime:   time.Now(),
+		decays:      map[string]int{},
+		progress:    map[string]int{},
+		portalLinks: map[[2]int][2]int{},
+		entities:    scr.NewLayer(1, false),
+		hud:         scr.NewLayer(2, true),
+		fpsTracker:  0,
+		stopping:    false,
+		paused:      false,
 	}
+	game.ApplyTheme(theme)
+	game.DrawBorder()
 
 	if headless {
 		return game, nil
 	}
 
 	game.State.Players = map[string]Player{"0": {
-		Crd: [2]int{int(game.Screen.CurX / 2), int(game.Screen.CurY / 2)},
+		Crd: [2]int{int(game.Board.CurX / 2), int(game.Board.CurY / 2)},
 		Dir: "right", CurDir: "right",
 		TailCrds: [][2]int{},
 	}}
-	_ = game.Screen.SetColRow(game.State.Players[game.Config.ClientId].Crd[0], game.State.Players[game.Config.ClientId].Crd[1], ObjPlayer)
+	_ = game.Board.SetColRow(game.State.Players[game.Config.ClientId].Crd[0], game.State.Players[game.Config.ClientId].Crd[1], ObjPlayer)
 
-	game.Screen.OnResizeCallback = func(scr *screen.Screen) {
-		_ = scr.SetCol(0, ObjWall)
-		_ = scr.SetRow(0, ObjWall)
-		_ = scr.SetCol(scr.CurX, ObjWall)
-		_ = scr.SetRow(scr.CurY, ObjWall)
+	return game, nil
+}
 
-		if game.State.PlusOneActive {
-			game.Screen.RenderStringIf("+", 2, 2, ObjPlusOne, func(val uint8) bool { return val == ObjEmpty })
-			game.Screen.RenderStringIf("1", 8, 2, ObjPlusOne, func(val uint8) bool { return val == ObjEmpty })
+// Replace all players with `count` players sharing this terminal, spread around the center.
+func (game *Game) SetLocalPlayers(count int) {
+	for _, player := range game.State.Players {
+		_ = game.Board.SetColRow(player.Crd[0], player.Crd[1], ObjEmpty)
+		for _, cord := range player.TailCrds {
+			_ = game.Board.SetColRow(cord[0], cord[1], ObjEmpty)
 		}
+	}
 
-		if game.paused {
-			game.Screen.RenderStringIf("Paused", 2, 2, ObjWarning, func(val uint8) bool { return val < ObjPlayer })
+	game.Config.LocalPlayers = min(max(1, count), MaxLocalPlayers)
+	game.State.Players = make(map[string]Player, game.Config.LocalPlayers)
+
+	for i, id := range game.localIds() {
+		game.State.Players[id] = Player{
+			Crd: game.StartCrd(i),
+			Dir: "right", CurDir: "right",
+			TailCrds: [][2]int{},
+			Color:    i,
 		}
+		_ = game.Board.SetColRow(game.State.Players[id].Crd[0], game.State.Players[id].Crd[1], game.playerObj(id))
+	}
+}
+
+// Get the ids of all players controlled from this terminal.
+func (game *Game) localIds() []string {
+	if game.Config.LocalPlayers <= 1 {
+		return []string{game.Config.ClientId}
+	}
 
-		_ = scr.SetColRow(game.State.Players[game.Config.ClientId].Crd[0], game.State.Players[game.Config.ClientId].Crd[1], ObjPlayer)
+	ids := []string{}
+	for i := range game.Config.LocalPlayers {
+		ids = append(ids, strconv.Itoa(i))
+	}
+	return ids
+}
 
-		for _, cord := range game.State.Players[game.Config.ClientId].TailCrds {
-			_ = scr.SetColRow(cord[0], cord[1], ObjPlayer)
+// Get the object a player is drawn with, local players each get their own object.
+func (game *Game) playerObj(id string) uint8 {
+	if _, ok := game.Bots[id]; ok {
+		return ObjBot
+	}
+	if game.Config.LocalPlayers <= 1 {
+		return ObjPlayer
+	}
+
+	i, err := strconv.Atoi(id)
+	if err != nil || i < 0 || i >= MaxLocalPlayers {
+		return ObjPlayer
+	}
+	return ObjPlayer + uint8(i)
+}
+
+// Get the lowest player color of the theme no player is using yet, colors repeat once all are taken.
+func (game *Game) NextColor() int {
+	colors := len(game.Theme.Players)
+	used := map[int]bool{}
+	for _, playerState := range game.State.Players {
+		used[playerState.Color%colors] = true
+	}
+	for i := range colors {
+		if !used[i] {
+			return i
 		}
-		for _, cord := range game.State.PeaCrds {
-			_ = scr.SetColRow(cord[0], cord[1], ObjPea)
+	}
+	return len(game.State.Players) % colors
+}
+
+// Get the escape code of the color player `id` is drawn with.
+func (game *Game) playerColor(id string) string {
+	return game.color(game.Theme.Players[game.State.Players[id].Color%len(game.Theme.Players)])
+}
+
+// Check if all players controlled from this terminal are game over.
+func (game *Game) isGameOver() bool {
+	for _, id := range game.localIds() {
+		if !game.State.Players[id].IsGameOver {
+			return false
 		}
-		_ = game.Screen.Draw()
 	}
+	return true
+}
 
-	return game, nil
+// Draw the board with the view following the head of the first local player still alive, with the text overlay on top.
+func (game *Game) draw() error {
+	for _, id := range game.localIds() {
+		if playerState, ok := game.State.Players[id]; ok && !playerState.IsGameOver {
+			game.Screen.Follow(playerState.Crd[0], playerState.Crd[1])
+			break
+		}
+	}
+
+	game.Screen.Show(game.Board.Rows)
+	game.drawSnakes()
+	game.hud.Clear()
+	if game.State.PlusOneActive {
+		game.hud.RenderString("+1", 2, 2, ObjPlusOne)
+	}
+	if game.isGameOver() {
+		game.hud.RenderString("Game", 2, 2, ObjWarning)
+		game.hud.RenderString("Over", 8, 8, ObjWarning)
+	} else if game.paused {
+		game.hud.RenderString("Paused", 2, 2, ObjWarning)
+	}
+	return game.Screen.Draw()
+}
+
+// Paint every snake in its color onto the entity layer, snakes of local players are solid and heads are drawn over bodies.
+func (game *Game) drawSnakes() {
+	game.entities.Clear()
+
+	local := game.localIds()
+	for id, playerState := range game.State.Players {
+		if playerState.Decayed {
+			continue
+		}
+		color := uint8(playerState.Color % len(game.Theme.Players))
+		body := ObjSnakeOther + color
+		if slices.Contains(local, id) {
+			body = ObjSnakeOwn + color
+		}
+		for _, crd := range playerState.TailCrds {
+			_ = game.entities.SetColRow(crd[0], crd[1], body)
+		}
+	}
+	for _, playerState := range game.State.Players {
+		if !playerState.Decayed {
+			_ = game.entities.SetColRow(playerState.Crd[0], playerState.Crd[1], ObjSnakeHead+uint8(playerState.Color%len(game.Theme.Players)))
+		}
+	}
 }
 
 func (game *Game) statsBar() {
 	timeDiff := time.Since(game.StartTime)
 	timeStr := fmt.Sprintf("%02d:%02d:%02d:%03d", int(timeDiff.Hours()), int(timeDiff.Minutes())%60, int(timeDiff.Seconds())%60, int(timeDiff.Milliseconds())%1000)
 
+	viewX, viewY := game.Screen.ViewSize()
+	width, _ := game.Screen.TermSize()
 	sizeXColor := ""
-	if game.Config.Connection != nil && game.Screen.CurX < game.Screen.MaxX {
-		sizeXColor = Red
+	if viewX <= game.Board.CurX {
+		sizeXColor = game.color(game.Theme.Alert)
 	}
 	sizeYColor := ""
-	if game.Config.Connection != nil && game.Screen.CurY < game.Screen.MaxY {
-		sizeYColor = Red
+	if viewY <= game.Board.CurY {
+		sizeYColor = game.color(game.Theme.Alert)
 	}
 
 	fpsColor := ""
 	if !game.Config.LockFPSToTPS && game.fpsTracker < game.Config.TargetFPS-(game.Config.TargetFPS/5) {
-		fpsColor = Red
+		fpsColor = game.color(game.Theme.Alert)
 	}
 	tpsColor := ""
 	if game.State.TpsTracker < game.Config.TargetTPS {
-		tpsColor = Red
+		tpsColor = game.color(game.Theme.Alert)
+	}
+
+	peasStr := strconv.Itoa(len(game.State.Players[game.Config.ClientId].TailCrds))
+	if game.Config.LocalPlayers > 1 {
+		peas := []string{}
+		for i, id := range game.localIds() {
+			peas = append(peas, game.paint(game.playerColor(id), "P"+strconv.Itoa(i+1)+" "+strconv.Itoa(len(game.State.Players[id].TailCrds))))
+		}
+		peasStr = strings.Join(peas, " ")
+	}
+
+	if game.Config.LocalPlayers <= 1 {
+		peasStr += "   Score: " + strconv.Itoa(game.State.Players[game.Config.ClientId].Score.Points)
+		peasStr += "   Speed: " + strconv.Itoa(game.SpeedOf(game.Config.ClientId)) + "/s"
 	}
 
 	msg := fmt.Sprintf("Time: %v   Peas: %v   Size: %vx %vy   FPS: %v   TPS: %v ",
 		timeStr,
-		len(game.State.Players[game.Config.ClientId].TailCrds),
-		sizeXColor+strconv.Itoa(game.Screen.CurX)+Reset,
-		sizeYColor+strconv.Itoa(game.Screen.CurY)+Reset,
-		fpsColor+strconv.Itoa(game.fpsTracker)+Reset,
-		tpsColor+strconv.Itoa(game.State.TpsTracker)+Reset,
+		peasStr,
+		game.paint(sizeXColor, strconv.Itoa(game.Board.CurX)),
+		game.paint(sizeYColor, strconv.Itoa(game.Board.CurY)),
+		game.paint(fpsColor, strconv.Itoa(game.fpsTracker)),
+		game.paint(tpsColor, strconv.Itoa(game.State.TpsTracker)),
 	)
+	if game.Autopilot {
+		msg += "  " + game.paint(game.color(game.Theme.Highlight), "Autopilot") + " "
+	}
+	for _, pickup := range Pickups {
+		if left := game.State.Players[game.Config.ClientId].Effects[pickup.Name]; left > 0 {
+			msg += "  " + game.paint(game.pickupColor(pickup), pickup.Name+" "+strconv.Itoa(left/max(1, game.Config.PlayerSpeed)+1)+"s") + " "
+		}
+	}
 
-	if len([]rune(msg)) > game.Screen.CurX*2 {
-		fmt.Printf("\033[2K\r%."+strconv.Itoa(game.Screen.CurX*2)+"s...", msg)
+	if game.resizePaused {
+		boardX, boardY := game.Board.CurX+1, game.Board.CurY+1
+		if game.Screen.HalfBlock {
+			boardY = (boardY + 1) / 2
+		} else {
+			boardX *= 2
+		}
+		msg = fmt.Sprintf("Terminal too small for %vx %vy, resize it or press %v to play with part of the arena in view ",
+			boardX, boardY+1, strings.Join(game.KeyBinds.Names(ActionPause), "/"))
+	}
+
+	if len([]rune(msg)) > width-2 {
+		fmt.Printf("\033[2K\r%."+strconv.Itoa(width-2)+"s...", msg)
 	} else {
-		fmt.Printf("\033[2K\r%."+strconv.Itoa(game.Screen.CurX*2)+"s", msg)
+		fmt.Printf("\033[2K\r%."+strconv.Itoa(width-2)+"s", msg)
 	}
 }
 
 func (game *Game) HandleInput(in []byte) error {
-	playerState := game.State.Players[game.Config.ClientId]
-	if slices.Equal(in, game.KeyBinds.CTRL_C) || slices.Equal(in, game.KeyBinds.CTRL_D) || slices.Equal(in, game.KeyBinds.Q) {
+	player, action := SplitAction(game.KeyBinds.Action(in))
+	id := game.Config.ClientId
+	if game.Config.LocalPlayers > 1 {
+		if player >= game.Config.LocalPlayers {
+			return nil
+		}
+		id = strconv.Itoa(player)
+	}
+
+	playerState := game.State.Players[id]
+	if action == ActionQuit {
 		game.stopping = true
 		return nil
 
-	} else if playerState.IsGameOver {
+	} else if action == ActionMinimap {
+		game.Screen.Minimap = !game.Screen.Minimap
+		return nil
+	} else if game.isGameOver() {
+		return nil
+	} else if action == ActionAutopilot && game.Config.Connection == nil {
+		game.Autopilot = !game.Autopilot
 		return nil
-	} else if (slices.Equal(in, game.KeyBinds.ESC) || slices.Equal(in, game.KeyBinds.P)) && game.Config.Connection == nil {
+	} else if action == ActionPause && game.Config.Connection == nil {
 		game.paused = !game.paused
-		if game.paused {
-			game.Screen.RenderStringIf("Paused", 2, 2, ObjWarning, func(val uint8) bool { return val < ObjPlayer })
-		} else {
-			game.Screen.RenderStringIf("Paused", 2, 2, ObjEmpty, func(val uint8) bool { return val < ObjPlayer })
-		}
-		err := game.Screen.Draw()
+		game.resizePaused = false
+		err := game.draw()
 		if err != nil {
 			return err
 		}
 		return nil
 	}
 
+	if playerState.IsGameOver {
+		return nil
+	}
+
 	dir := playerState.Dir
-	if !game.paused && playerState.CurDir != "down" && (slices.Equal(in, game.KeyBinds.W) || slices.Equal(in, game.KeyBinds.K) || slices.Equal(in, game.KeyBinds.UP)) {
+	if !game.paused && playerState.CurDir != "down" && action == ActionUp {
 		dir = "up"
-	} else if !game.paused && playerState.CurDir != "left" && (slices.Equal(in, game.KeyBinds.D) || slices.Equal(in, game.KeyBinds.L) || slices.Equal(in, game.KeyBinds.RIGHT)) {
+	} else if !game.paused && playerState.CurDir != "left" && action == ActionRight {
 		dir = "right"
-	} else if !game.paused && playerState.CurDir != "up" && (slices.Equal(in, game.KeyBinds.S) || slices.Equal(in, game.KeyBinds.J) || slices.Equal(in, game.KeyBinds.DOWN)) {
+	} else if !game.paused && playerState.CurDir != "up" && action == ActionDown {
 		dir = "down"
-	} else if !game.paused && playerState.CurDir != "right" && (slices.Equal(in, game.KeyBinds.A) || slices.Equal(in, game.KeyBinds.H) || slices.Equal(in, game.KeyBinds.LEFT)) {
+	} else if !game.paused && playerState.CurDir != "right" && action == ActionLeft {
 		dir = "left"
 	}
 
@@ -257,48 +520,407 @@ func (game *Game) HandleInput(in []byte) error {
 	}
 
 	playerState.Dir = dir
-	game.State.Players[game.Config.ClientId] = playerState
+	game.State.Players[id] = playerState
 	return nil
 }
 
-func (game *Game) UpdatePlayer(id string) {
-	playerState := game.State.Players[id]
+// Check which edges are portals.
+func (game *Game) portals() (horizontal, vertical bool) {
+	return edgePortals(game.Config.WallMode)
+}
 
-	oldCords := playerState.Crd
-	switch playerState.Dir {
+// Check which edges are portals in `wallMode`, unknown wall modes wrap.
+func edgePortals(wallMode string) (horizontal, vertical bool) {
+	switch wallMode {
+	case WallsSolid:
+		return false, false
+	case WallsHorizontal:
+		return true, false
+	case WallsVertical:
+		return false, true
+	}
+	return true, true
+}
+
+// Draw the border of the arena, portal edges are only drawn as portals when some edges are walls.
+func (game *Game) DrawBorder() {
+	drawEdges(game.Board, game.Config.WallMode)
+	game.drawArena()
+}
+
+// Draw the outer ring of `scr` following `wallMode`.
+func drawEdges(board *Board, wallMode string) {
+	horizontal, vertical := edgePortals(wallMode)
+
+	colObj, rowObj := ObjWall, ObjWall
+	if horizontal != vertical {
+		if horizontal {
+			colObj = ObjPortal
+		} else {
+			rowObj = ObjPortal
+		}
+	}
+
+	_ = board.SetCol(0, colObj)
+	_ = board.SetCol(board.CurX, colObj)
+	_ = board.SetRow(0, rowObj)
+	_ = board.SetRow(board.CurY, rowObj)
+}
+
+// Rank objects on the minimap, snakes are drawn over peas and pickups, over portals, over walls.
+func minimapRank(val uint8) int {
+	switch {
+	case val >= ObjPlayer:
+		return 4
+	case val == ObjPea || val >= ObjSpeed:
+		return 3
+	case val == ObjPortal:
+		return 2
+	case val == ObjWall:
+		return 1
+	}
+	return 0
+}
+
+// Check if moving onto a cell holding `val` ends the game.
+func IsDeadly(val uint8) bool {
+	return val == ObjWall || val >= ObjPlayer
+}
+
+// Get the cords one step from `crd` in direction `dir`, wrapping around portal edges and through portals of the arena.
+//
+// Moving into a wall edge returns the cords of the wall.
+func (game *Game) NextCrd(crd [2]int, dir string) [2]int {
+	next := game.wrapCrd(crd, dir)
+	link, ok := game.portalLinks[next]
+	if !ok {
+		return next
+	}
+	// Portals leading straight into another portal act like an empty cell.
+	exit := game.wrapCrd(link, dir)
+	if _, ok := game.portalLinks[exit]; ok {
+		return next
+	}
+	return exit
+}
+
+// Get the cords one step from `crd` in direction `dir`, only wrapping around portal edges.
+func (game *Game) wrapCrd(crd [2]int, dir string) [2]int {
+	switch dir {
 	case "up":
-		playerState.Crd[1] -= 1
+		crd[1] -= 1
 	case "right":
-		playerState.Crd[0] += 1
+		crd[0] += 1
 	case "down":
-		playerState.Crd[1] += 1
+		crd[1] += 1
 	case "left":
-		playerState.Crd[0] -= 1
+		crd[0] -= 1
 	}
-	playerState.CurDir = playerState.Dir
 
-	if playerState.Crd[0] <= 0 {
-		playerState.Crd[0] = game.Screen.CurX - 1
-	} else if playerState.Crd[0] >= game.Screen.CurX {
-		playerState.Crd[0] = 1
-	} else if playerState.Crd[1] <= 0 {
-		playerState.Crd[1] = game.Screen.CurY - 1
-	} else if playerState.Crd[1] >= game.Screen.CurY {
-		playerState.Crd[1] = 1
+	horizontal, vertical := game.portals()
+	if crd[0] <= 0 && horizontal {
+		crd[0] = game.Board.CurX - 1
+	} else if crd[0] >= game.Board.CurX && horizontal {
+		crd[0] = 1
+	} else if crd[1] <= 0 && vertical {
+		crd[1] = game.Board.CurY - 1
+	} else if crd[1] >= game.Board.CurY && vertical {
+		crd[1] = 1
 	}
+	return crd
+}
 
-	val, err := game.Screen.GetColRow(playerState.Crd[0], playerState.Crd[1])
-	if err != nil {
+// Move all players in `ids` that are still alive one tick, applying the effects of their pickups.
+func (game *Game) UpdatePlayers(ids []string) {
+	game.moves++
+
+	movers := []string{}
+	for _, id := range ids {
+		playerState, ok := game.State.Players[id]
+		if !ok || playerState.IsGameOver || (game.moves%2 == 1 && game.isSlowed(id)) {
+			continue
+		}
+		movers = append(movers, id)
+	}
+
+	game.step(movers)
+	if fast := slices.DeleteFunc(movers, func(id string) bool {
+		return game.State.Players[id].IsGameOver || !game.HasEffect(id, EffectSpeed)
+	}); len(fast) > 0 {
+		game.step(fast)
+	}
+
+	game.tickPlayers(ids)
+}
+
+// Count a player tick for all players in `ids`, updating their score, speed, effects and the decay of dead bodies.
+func (game *Game) tickPlayers(ids []string) {
+	for _, id := range ids {
+		playerState, ok := game.State.Players[id]
+		if !ok {
+			continue
+		}
+		if !playerState.IsGameOver {
+			playerState.Score.Moves++
+		}
+		playerState.Score.tally(game.Config.PlayerSpeed)
+		playerState.Speed = game.curveSpeed(playerState.Score)
 		game.State.Players[id] = playerState
-		return
 	}
 
-	if val == ObjPlayer {
-		playerState.Crd = oldCords
+	game.updateEffects(ids)
+	game.updateDecays()
+}
+
+// Move all players in `ids` one step at once.
+//
+// Collisions are checked against where everything is after the move, tails move along unless their snake eats a pea.
+// Snakes that die stay where they are, so the result does not depend on the order of `ids`.
+// Heads meeting in the same cell or swapping cells all die, unless `ShorterDies` is set and one of them is strictly the longest.
+// The survivor of a swap bites off the head of the other snake and takes its cell.
+// Running into a body credits the kill to its owner, ghosts only pass through bodies and die by walls and heads.
+func (game *Game) step(ids []string) {
+	targets := map[[2]int][]string{}
+	next := map[string][2]int{}
+	for _, id := range ids {
+		playerState, ok := game.State.Players[id]
+		if !ok || playerState.IsGameOver {
+			continue
+		}
+		crd := game.NextCrd(playerState.Crd, playerState.Dir)
+		targets[crd] = append(targets[crd], id)
+		next[id] = crd
+	}
+
+	heads := map[[2]int]string{}
+	for id, playerState := range game.State.Players {
+		if !playerState.IsGameOver {
+			heads[playerState.Crd] = id
+		}
+	}
+
+	killers := map[string]string{}
+	// Survivors of swaps by the id of the snake whose head they take.
+	biters := map[string]string{}
+	for id, crd := range next {
+		other, ok := heads[crd]
+		if !ok || other == id || id > other {
+			continue
+		}
+		if otherCrd, ok := next[other]; !ok || otherCrd != game.State.Players[id].Crd {
+			continue
+		}
+		survivor := ""
+		if game.Config.ShorterDies {
+			survivor = game.longest([]string{id, other})
+		}
+		for _, pair := range [][2]string{{id, other}, {other, id}} {
+			if pair[0] == survivor {
+				biters[pair[0]] = pair[1]
+			} else {
+				killers[pair[0]] = survivor
+			}
+		}
+	}
+
+	owners := game.bodyOwners()
+	// Every death keeps a tail in place that was expected to move away, so check again until no one else dies.
+	for changed := true; changed; {
+		changed = false
+		vacated := game.vacated(next, killers)
+		for crd, movers := range targets {
+			val, err := game.Board.GetColRow(crd[0], crd[1])
+			blocked := err == nil && IsDeadly(val) && !vacated[crd]
+			// Heads of players that do not move kill ghosts too.
+			_, moving := next[heads[crd]]
+			isHead := heads[crd] != "" && !moving
+
+			alive := []string{}
+			for _, id := range movers {
+				if _, ok := killers[id]; ok {
+					continue
+				}
+				if _, ok := biters[id]; !ok && blocked && (val < ObjPlayer || isHead || !game.HasEffect(id, EffectGhost)) {
+					killers[id] = owners[crd]
+					changed = true
+					continue
+				}
+				alive = append(alive, id)
+			}
+			if len(alive) < 2 {
+				continue
+			}
+
+			survivor := ""
+			if game.Config.ShorterDies {
+				survivor = game.longest(alive)
+			}
+			for _, id := range alive {
+				if id != survivor {
+					killers[id] = survivor
+					changed = true
+				}
+			}
+		}
+	}
+
+	for id, killer := range killers {
+		playerState := game.State.Players[id]
+		playerState.CurDir = playerState.Dir
 		playerState.IsGameOver = true
-		game.Screen.RenderString("Game", 2, 2, ObjWarning)
-		game.Screen.RenderString("Over", 8, 8, ObjWarning)
+		if killer != id {
+			playerState.KilledBy = killer
+		}
+		game.State.Players[id] = playerState
+
+		if killerState, ok := game.State.Players[killer]; ok && killer != id {
+			killerState.Score.Kills++
+			killerState.Score.tally(game.Config.PlayerSpeed)
+			game.State.Players[killer] = killerState
+		}
+	}
 
+	for _, id := range biters {
+		game.biteHead(id)
+	}
+
+	ghosts := false
+	for crd, movers := range targets {
+		for _, id := range movers {
+			if _, ok := killers[id]; !ok {
+				ghosts = ghosts || game.HasEffect(id, EffectGhost)
+				game.movePlayer(id, crd)
+			}
+		}
+	}
+	if ghosts {
+		game.paintPlayers()
+		return
+	}
+	// Heads entering a cell left by a tail in this step are cleared again when that tail moved after them.
+	for id := range next {
+		if _, ok := killers[id]; !ok {
+			crd := game.State.Players[id].Crd
+			_ = game.Board.SetColRow(crd[0], crd[1], game.playerObj(id))
+		}
+	}
+}
+
+// Get the cells left behind by the players in `next` moving there, players in `killers` and players eating a pea keep their tail in place.
+func (game *Game) vacated(next map[string][2]int, killers map[string]string) map[[2]int]bool {
+	cells := map[[2]int]bool{}
+	for id, crd := range next {
+		if _, ok := killers[id]; ok {
+			continue
+		}
+		if val, err := game.Board.GetColRow(crd[0], crd[1]); err == nil && val == ObjPea {
+			continue
+		}
+		playerState := game.State.Players[id]
+		if len(playerState.TailCrds) > 0 {
+			cells[playerState.TailCrds[0]] = true
+		} else {
+			cells[playerState.Crd] = true
+		}
+	}
+	return cells
+}
+
+// Remove the head of the dead player `id`, the cell after it becomes the new head.
+func (game *Game) biteHead(id string) {
+	playerState := game.State.Players[id]
+	if last := len(playerState.TailCrds) - 1; last >= 0 {
+		playerState.Crd = playerState.TailCrds[last]
+		playerState.TailCrds = playerState.TailCrds[:last]
+	} else {
+		playerState.Decayed = true
+	}
+	game.State.Players[id] = playerState
+}
+
+// Count down the bodies of dead players and decay them once their delay passed, does nothing when bodies stay.
+func (game *Game) updateDecays() {
+	if game.Config.DeadBodies != BodiesPeas && game.Config.DeadBodies != BodiesFade {
+		return
+	}
+
+	for id, playerState := range game.State.Players {
+		if !playerState.IsGameOver || playerState.Decayed {
+			continue
+		}
+		left, ok := game.decays[id]
+		if !ok {
+			left = game.Config.DecayDelay * game.Config.PlayerSpeed
+		}
+		if left > 0 {
+			game.decays[id] = left - 1
+			continue
+		}
+		delete(game.decays, id)
+		game.decayBody(id)
+	}
+}
+
+// Remove the body of player `id` from the board, every other cell becomes a pea when bodies decay into peas.
+func (game *Game) decayBody(id string) {
+	playerState := game.State.Players[id]
+
+	for i, crd := range append([][2]int{playerState.Crd}, playerState.TailCrds...) {
+		val, err := game.Board.GetColRow(crd[0], crd[1])
+		if err != nil || val < ObjPlayer {
+			continue
+		}
+		if game.Config.DeadBodies == BodiesPeas && i%2 == 0 {
+			game.State.PeaCrds = append(game.State.PeaCrds, crd)
+			_ = game.Board.SetColRow(crd[0], crd[1], ObjPea)
+			continue
+		}
+		_ = game.Board.SetColRow(crd[0], crd[1], ObjEmpty)
+	}
+
+	playerState.TailCrds = [][2]int{}
+	playerState.Decayed = true
+	game.State.Players[id] = playerState
+}
+
+// Get the id of the player occupying each cell, including players that are game over.
+func (game *Game) bodyOwners() map[[2]int]string {
+	owners := map[[2]int]string{}
+	for id, playerState := range game.State.Players {
+		if playerState.Decayed {
+			continue
+		}
+		owners[playerState.Crd] = id
+		for _, crd := range playerState.TailCrds {
+			owners[crd] = id
+		}
+	}
+	return owners
+}
+
+// Get the strictly longest player of `ids`, empty when the longest are equally long.
+func (game *Game) longest(ids []string) string {
+	best, bestLen := "", -1
+	for _, id := range ids {
+		if l := len(game.State.Players[id].TailCrds); l > bestLen {
+			best, bestLen = id, l
+		} else if l == bestLen {
+			best = ""
+		}
+	}
+	return best
+}
+
+// Move player `id` onto `crd`, which should be free of collisions.
+func (game *Game) movePlayer(id string, crd [2]int) {
+	playerState := game.State.Players[id]
+
+	oldCords := playerState.Crd
+	playerState.Crd = crd
+	playerState.CurDir = playerState.Dir
+
+	val, err := game.Board.GetColRow(playerState.Crd[0], playerState.Crd[1])
+	if err != nil {
 		game.State.Players[id] = playerState
 		return
 	}
@@ -309,10 +931,15 @@ func (game *Game) UpdatePlayer(id string) {
 		})
 
 		playerState.TailCrds = append(playerState.TailCrds, oldCords)
+		playerState.Score.Peas++
+		if playerState.Effects[EffectDouble] > 0 {
+			playerState.Score.Bonus += PointsPea
+		}
+		if (len(playerState.TailCrds)+1)%MilestoneLength == 0 {
+			playerState.Score.Milestones++
+		}
 
 		game.State.PlusOneActive = true
-		game.Screen.RenderStringIf("+", 2, 2, ObjPlusOne, func(val uint8) bool { return val == ObjEmpty })
-		game.Screen.RenderStringIf("1", 8, 2, ObjPlusOne, func(val uint8) bool { return val == ObjEmpty })
 
 	} else {
 		if len(playerState.TailCrds) > 0 {
@@ -320,38 +947,87 @@ func (game *Game) UpdatePlayer(id string) {
 			oldCords = playerState.TailCrds[0]
 			playerState.TailCrds = slices.Delete(playerState.TailCrds, 0, 1)
 		}
-		_ = game.Screen.SetColRow(oldCords[0], oldCords[1], ObjEmpty)
+		_ = game.Board.SetColRow(oldCords[0], oldCords[1], ObjEmpty)
 	}
 
-	_ = game.Screen.SetColRow(playerState.Crd[0], playerState.Crd[1], ObjPlayer)
+	_ = game.Board.SetColRow(playerState.Crd[0], playerState.Crd[1], game.playerObj(id))
 	game.State.Players[id] = playerState
+
+	if pickup, ok := pickupByObj(val); ok {
+		game.collect(id, crd, pickup)
+	}
 }
 
 func (game *Game) SpawnPea() {
 	for i := 1; i < 100; i++ {
-		cord := [2]int{rand.IntN(game.Screen.CurX-1) + 1, rand.IntN(game.Screen.CurY-1) + 1}
-		val, _ := game.Screen.GetColRow(cord[0], cord[1])
+		cord := game.peaCrd()
+		val, _ := game.Board.GetColRow(cord[0], cord[1])
 		if val == ObjEmpty {
 			game.State.PeaCrds = append(game.State.PeaCrds, cord)
-			_ = game.Screen.SetColRow(cord[0], cord[1], ObjPea)
+			_ = game.Board.SetColRow(cord[0], cord[1], ObjPea)
 			break
 		}
 	}
 }
 
+// Forget peas that are no longer on the board and spawn a new one when below the spawn limit.
+func (game *Game) UpdatePeas() {
+	game.State.PeaCrds = slices.DeleteFunc(game.State.PeaCrds, func(cord [2]int) bool {
+		val, err := game.Board.GetColRow(cord[0], cord[1])
+		return err != nil || val != ObjPea
+	})
+
+	if len(game.State.PeaCrds) < game.Config.PeaSpawnLimit {
+		game.SpawnPea()
+	}
+	if game.Config.Pickups {
+		game.UpdatePickups()
+	}
+}
+
+// Get the amount of player moves between pea spawns, for simulations that step per player move.
+func (game *Game) PlayerMovesPerPea() int {
+	return max(1, (game.Config.PeaSpawnDelay*game.Config.TargetTPS)/max(1, game.Config.TargetTPS/game.Config.PlayerSpeed))
+}
+
+// Apply a size change of the terminal following the resize policy, the board and all players stay as they are.
+func (game *Game) handleResize() {
+	game.Screen.UpdateSize()
+	game.Screen.ClearNext()
+
+	if game.Config.ResizePolicy != ResizePause || game.Config.Connection != nil {
+		return
+	}
+	viewX, viewY := game.Screen.ViewSize()
+	fits := viewX > game.Board.CurX && viewY > game.Board.CurY
+	if !fits && !game.paused && !game.isGameOver() {
+		game.paused, game.resizePaused = true, true
+	} else if fits && game.resizePaused {
+		game.paused, game.resizePaused = false, false
+	}
+}
+
+// Handle a pending size change of the terminal, does nothing while not watching the terminal.
+func (game *Game) checkResize() {
+	select {
+	case <-game.resizes:
+		game.handleResize()
+	default:
+	}
+}
+
 func (game *Game) loopSingle(iteration int) {
 	now := time.Now()
+	game.checkResize()
 	updateFramePlayer := max(1, game.Config.TargetTPS/game.Config.PlayerSpeed)
 	updateFramePea := max(1, game.Config.PeaSpawnDelay*game.Config.TargetTPS)
 	updateFramePlusOne := max(1, game.Config.PlusOneDelay*game.Config.TargetTPS)
 
 	if game.State.PlusOneActive && iteration%updateFramePlusOne == 0 {
 		game.State.PlusOneActive = false
-		game.Screen.RenderStringIf("+", 2, 2, ObjEmpty, func(val uint8) bool { return val == ObjPlusOne })
-		game.Screen.RenderStringIf("1", 8, 2, ObjEmpty, func(val uint8) bool { return val == ObjPlusOne })
 	}
 
-	if game.paused || game.State.Players[game.Config.ClientId].IsGameOver {
+	if game.paused || game.isGameOver() {
 		time.Sleep((time.Second / time.Duration(game.Config.TargetTPS)) - time.Since(now))
 		game.State.TpsTracker = int(time.Second/time.Since(now)) + 1
 		game.StartTime = game.StartTime.Add(time.Since(now))
@@ -365,27 +1041,17 @@ func (game *Game) loopSingle(iteration int) {
 	}
 
 	if iteration%updateFramePlayer == 0 {
-		_ = game.Screen.SetCol(0, ObjWall)
-		_ = game.Screen.SetRow(0, ObjWall)
-		_ = game.Screen.SetCol(game.Screen.CurX, ObjWall)
-		_ = game.Screen.SetRow(game.Screen.CurY, ObjWall)
-
-		game.UpdatePlayer("0")
+		game.DrawBorder()
 	}
+	game.UpdateFrame(append(game.localIds(), slices.Collect(maps.Keys(game.Bots))...), iteration)
 
 	if iteration%updateFramePea == 0 {
-		game.State.PeaCrds = slices.DeleteFunc(game.State.PeaCrds, func(cord [2]int) bool {
-			val, err := game.Screen.GetColRow(cord[0], cord[1])
-			return err != nil || val != ObjPea
-		})
-
-		if len(game.State.PeaCrds) < game.Config.PeaSpawnLimit {
-			game.SpawnPea()
-		}
+		game.UpdatePeas()
 	}
 
 	if game.Config.LockFPSToTPS {
-		_ = game.Screen.Draw()
+		_ = game.draw()
+		game.overlay()
 		time.Sleep((time.Second / time.Duration(game.Config.TargetTPS)) - time.Since(now))
 		game.State.TpsTracker = int(time.Second/time.Since(now)) + 1
 
@@ -398,6 +1064,61 @@ func (game *Game) loopSingle(iteration int) {
 	game.State.TpsTracker = int(time.Second/time.Since(now)) + 1
 }
 
+// Reset the arena and all players, bots keep their level but respawn.
+func (game *Game) reset() {
+	game.Board.Clear()
+	game.DrawBorder()
+
+	game.State.PeaCrds = [][2]int{}
+	game.State.Pickups = []PlacedPickup{}
+	game.State.PlusOneActive = false
+	game.decays = map[string]int{}
+	game.progress = map[string]int{}
+	game.SetLocalPlayers(game.Config.LocalPlayers)
+
+	bots := game.Bots
+	game.Bots = map[string]BotLevel{}
+	for _, level := range bots {
+		_, _ = game.AddBot(level)
+	}
+
+	for i := 0; i < game.Config.PeaStartCount; i++ {
+		game.SpawnPea()
+	}
+	game.StartTime = time.Now()
+}
+
+// Play an autopiloted game without reading input until `stop` is closed, restarting a few seconds after each game over.
+//
+// Frames are drawn at the TPS, the caller is responsible for the terminal state.
+func (game *Game) Demo(stop <-chan struct{}) {
+	game.Autopilot = true
+	game.Config.LockFPSToTPS = true
+	game.reset()
+
+	gameOverTicks := 0
+	for i := 1; ; i++ {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if game.isGameOver() {
+			gameOverTicks++
+			if gameOverTicks > game.Config.TargetTPS*3 {
+				gameOverTicks = 0
+				game.reset()
+			}
+		}
+		game.loopSingle(i)
+		if game.isGameOver() {
+			_ = game.draw()
+			game.overlay()
+		}
+	}
+}
+
 func (game *Game) loopMulti() {
 	reader := bufio.NewReader(game.Config.Connection)
 	for !game.stopping {
@@ -409,50 +1130,42 @@ func (game *Game) loopMulti() {
 			return
 		}
 		msg = strings.ReplaceAll(msg, "\n", "")
+		game.checkResize()
 
 		err = json.Unmarshal([]byte(msg), &game.State)
 		if err != nil {
 			panic(err)
 		}
 
-		for i := 0; i <= game.Screen.CurX; i++ {
-			_ = game.Screen.SetCol(i, ObjEmpty)
+		for i := 0; i <= game.Board.CurX; i++ {
+			_ = game.Board.SetCol(i, ObjEmpty)
 		}
-		for i := 0; i <= game.Screen.CurY; i++ {
-			_ = game.Screen.SetRow(i, ObjEmpty)
+		for i := 0; i <= game.Board.CurY; i++ {
+			_ = game.Board.SetRow(i, ObjEmpty)
 		}
 
-		_ = game.Screen.SetCol(0, ObjWall)
-		_ = game.Screen.SetRow(0, ObjWall)
-		_ = game.Screen.SetCol(game.Screen.CurX, ObjWall)
-		_ = game.Screen.SetRow(game.Screen.CurY, ObjWall)
-
-		if game.State.PlusOneActive {
-			game.Screen.RenderStringIf("+", 2, 2, ObjPlusOne, func(val uint8) bool { return val == ObjEmpty })
-			game.Screen.RenderStringIf("1", 8, 2, ObjPlusOne, func(val uint8) bool { return val == ObjEmpty })
-		} else {
-			game.Screen.RenderStringIf("+", 2, 2, ObjEmpty, func(val uint8) bool { return val == ObjPlusOne })
-			game.Screen.RenderStringIf("1", 8, 2, ObjEmpty, func(val uint8) bool { return val == ObjPlusOne })
-		}
+		game.DrawBorder()
 
 		for _, peaCrd := range game.State.PeaCrds {
-			_ = game.Screen.SetColRow(peaCrd[0], peaCrd[1], ObjPea)
+			_ = game.Board.SetColRow(peaCrd[0], peaCrd[1], ObjPea)
+		}
+		for _, pickup := range game.State.Pickups {
+			_ = game.Board.SetColRow(pickup.Crd[0], pickup.Crd[1], pickup.Obj)
 		}
 
 		for _, player := range game.State.Players {
-			_ = game.Screen.SetColRow(player.Crd[0], player.Crd[1], ObjPlayer)
+			if player.Decayed {
+				continue
+			}
+			_ = game.Board.SetColRow(player.Crd[0], player.Crd[1], ObjPlayer)
 			for _, tailCrd := range player.TailCrds {
-				_ = game.Screen.SetColRow(tailCrd[0], tailCrd[1], ObjPlayer)
+				_ = game.Board.SetColRow(tailCrd[0], tailCrd[1], ObjPlayer)
 			}
 		}
 
-		if game.State.Players[game.Config.ClientId].IsGameOver {
-			game.Screen.RenderString("Game", 2, 2, ObjWarning)
-			game.Screen.RenderString("Over", 8, 8, ObjWarning)
-		}
-
 		if game.Config.LockFPSToTPS {
-			_ = game.Screen.Draw()
+			_ = game.draw()
+			game.overlay()
 
 			game.fpsTracker = game.State.TpsTracker
 			game.statsBar()
@@ -465,13 +1178,14 @@ func (game *Game) Start() error {
 		return errors.New("stdin/ stdout should be a terminal")
 	}
 
-	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	terminal, err := screen.EnterFullscreen()
 	if err != nil {
 		return err
 	}
+	defer terminal.Restore()
 
 	go func() {
-		defer term.Restore(int(os.Stdin.Fd()), oldState)
+		defer terminal.RestoreOnPanic()
 		for {
 			in := make([]byte, 3)
 			_, err := os.Stdin.Read(in)
@@ -487,21 +1201,32 @@ func (game *Game) Start() error {
 		}
 	}()
 
+	resizes, stopResizes := game.Screen.WatchResize()
+	defer stopResizes()
+	game.resizes = resizes
+	game.Screen.Letterbox = game.Config.ResizePolicy == ResizeLetterbox
+	game.handleResize()
+
 	game.StartTime = time.Now()
+	// Closed once the last frame is drawn, so nothing is drawn after leaving the alternate screen buffer.
+	drawn := make(chan struct{})
 	if !game.Config.LockFPSToTPS {
 		// TODO: Stop render updates during game updates and vice virsa due to possible race condition.
 		// Error: `fatal error: concurrent map read and map write`
 		// Repeatable when using high TargetTPS and/ or TargetFPS.
 		go func() {
-			defer func() { game.stopping = true; _ = term.Restore(int(os.Stdin.Fd()), oldState) }()
+			defer close(drawn)
+			defer func() { game.stopping = true }()
+			defer terminal.RestoreOnPanic()
 			for !game.stopping {
 				now := time.Now()
 
-				_ = game.Screen.Draw()
+				_ = game.draw()
+				game.overlay()
 
 				time.Sleep((time.Second / time.Duration(game.Config.TargetFPS)) - time.Since(now))
 				game.fpsTracker = int(time.Second/time.Since(now)) + 1
-				if !game.State.Players[game.Config.ClientId].IsGameOver {
+				if !game.isGameOver() {
 					game.statsBar()
 				}
 
@@ -520,7 +1245,9 @@ func (game *Game) Start() error {
 		}
 	}
 
-	fmt.Print("\033[0;0H\r\n" + string(game.Screen.CharMap[ObjWall]))
+	if !game.Config.LockFPSToTPS {
+		<-drawn
+	}
 	if game.Config.Connection != nil {
 		_ = game.Config.Connection.Close()
 	}
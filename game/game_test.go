@@ -0,0 +1,218 @@
+package game
+
+import (
+	"testing"
+)
+
+type snake struct {
+	id   string
+	head [2]int
+	dir  string
+	// Tail from its end up to the cell next to the head.
+	tail  [][2]int
+	ghost bool
+}
+
+// Get a headless game holding only `snakes`, placed on the board.
+func newStepGame(t *testing.T, shorterDies bool, snakes ...snake) *Game {
+	t.Helper()
+
+	game, err := NewGame(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	game.Config.ShorterDies = shorterDies
+	for _, s := range snakes {
+		playerState := Player{Crd: s.head, Dir: s.dir, CurDir: s.dir, TailCrds: append([][2]int{}, s.tail...), Effects: map[string]int{}}
+		if s.ghost {
+			playerState.Effects[EffectGhost] = 5
+		}
+		game.State.Players[s.id] = playerState
+		for _, crd := range append([][2]int{s.head}, s.tail...) {
+			if err := game.Board.SetColRow(crd[0], crd[1], ObjPlayer); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return game
+}
+
+// Check that player `id` is dead or alive, killed by `killer` with `kills` kills of its own.
+func checkPlayer(t *testing.T, game *Game, id string, dead bool, killer string, kills int) {
+	t.Helper()
+
+	playerState := game.State.Players[id]
+	if playerState.IsGameOver != dead {
+		t.Errorf("player %v: IsGameOver = %v, want %v", id, playerState.IsGameOver, dead)
+	}
+	if playerState.KilledBy != killer {
+		t.Errorf("player %v: KilledBy = %q, want %q", id, playerState.KilledBy, killer)
+	}
+	if playerState.Score.Kills != kills {
+		t.Errorf("player %v: Kills = %v, want %v", id, playerState.Score.Kills, kills)
+	}
+}
+
+func TestStepSameCell(t *testing.T) {
+	game := newStepGame(t, false,
+		snake{id: "a", head: [2]int{10, 10}, dir: "right", tail: [][2]int{{8, 10}, {9, 10}}},
+		snake{id: "b", head: [2]int{12, 10}, dir: "left"},
+	)
+	game.step([]string{"a", "b"})
+
+	checkPlayer(t, game, "a", true, "", 0)
+	checkPlayer(t, game, "b", true, "", 0)
+}
+
+func TestStepSwap(t *testing.T) {
+	game := newStepGame(t, false,
+		snake{id: "a", head: [2]int{10, 10}, dir: "right", tail: [][2]int{{8, 10}, {9, 10}}},
+		snake{id: "b", head: [2]int{11, 10}, dir: "left"},
+	)
+	game.step([]string{"a", "b"})
+
+	checkPlayer(t, game, "a", true, "", 0)
+	checkPlayer(t, game, "b", true, "", 0)
+}
+
+func TestStepSwapShorterDies(t *testing.T) {
+	game := newStepGame(t, true,
+		snake{id: "a", head: [2]int{10, 10}, dir: "right", tail: [][2]int{{8, 10}, {9, 10}}},
+		snake{id: "b", head: [2]int{11, 10}, dir: "left", tail: [][2]int{{12, 10}}},
+		snake{id: "c", head: [2]int{20, 20}, dir: "right"},
+	)
+	game.step([]string{"a", "b", "c"})
+
+	checkPlayer(t, game, "a", false, "", 1)
+	checkPlayer(t, game, "b", true, "a", 0)
+	checkPlayer(t, game, "c", false, "", 0)
+	if crd := game.State.Players["a"].Crd; crd != [2]int{11, 10} {
+		t.Errorf("survivor moved to %v, want the cell of the bitten off head", crd)
+	}
+	if crd := game.State.Players["b"].Crd; crd != [2]int{12, 10} {
+		t.Errorf("bitten off head left %v as head, want the cell after it", crd)
+	}
+}
+
+func TestStepBody(t *testing.T) {
+	game := newStepGame(t, false,
+		snake{id: "a", head: [2]int{11, 10}, dir: "down"},
+		snake{id: "b", head: [2]int{12, 11}, dir: "right", tail: [][2]int{{10, 11}, {11, 11}}},
+	)
+	game.step([]string{"a", "b"})
+
+	checkPlayer(t, game, "a", true, "b", 0)
+	checkPlayer(t, game, "b", false, "", 1)
+}
+
+func TestStepTail(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		pea  bool
+		// Snake in front of the tail of b, hitting a wall when set.
+		blocked bool
+		dead    bool
+	}{
+		{"moves away", false, false, false},
+		{"eats", true, false, true},
+		{"dies", false, true, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			game := newStepGame(t, false,
+				snake{id: "a", head: [2]int{10, 10}, dir: "down"},
+				snake{id: "b", head: [2]int{12, 11}, dir: "right", tail: [][2]int{{10, 11}, {11, 11}}},
+			)
+			if tc.pea {
+				_ = game.Board.SetColRow(13, 11, ObjPea)
+			}
+			if tc.blocked {
+				_ = game.Board.SetColRow(13, 11, ObjWall)
+			}
+			game.step([]string{"a", "b"})
+
+			killer, kills := "", 0
+			if tc.dead {
+				killer, kills = "b", 1
+			}
+			checkPlayer(t, game, "a", tc.dead, killer, 0)
+			checkPlayer(t, game, "b", tc.blocked, "", kills)
+			if val, _ := game.Board.GetColRow(10, 11); !tc.dead && val != ObjPlayer {
+				t.Errorf("cell left by the tail of b holds %v, want the head of a", val)
+			}
+		})
+	}
+}
+
+func TestStepFollow(t *testing.T) {
+	game := newStepGame(t, false,
+		snake{id: "a", head: [2]int{10, 10}, dir: "right"},
+		snake{id: "b", head: [2]int{11, 10}, dir: "right"},
+	)
+	game.step([]string{"a", "b"})
+
+	checkPlayer(t, game, "a", false, "", 0)
+	checkPlayer(t, game, "b", false, "", 0)
+	if crd := game.State.Players["a"].Crd; crd != [2]int{11, 10} {
+		t.Errorf("follower moved to %v, want %v", crd, [2]int{11, 10})
+	}
+}
+
+func TestStepShorterDies(t *testing.T) {
+	game := newStepGame(t, true,
+		snake{id: "a", head: [2]int{10, 10}, dir: "right", tail: [][2]int{{8, 10}, {9, 10}}},
+		snake{id: "b", head: [2]int{12, 10}, dir: "left"},
+	)
+	game.step([]string{"a", "b"})
+
+	checkPlayer(t, game, "a", false, "", 1)
+	checkPlayer(t, game, "b", true, "a", 0)
+	if crd := game.State.Players["a"].Crd; crd != [2]int{11, 10} {
+		t.Errorf("survivor moved to %v, want %v", crd, [2]int{11, 10})
+	}
+}
+
+func TestStepTie(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		b    snake
+	}{
+		{"same cell", snake{id: "b", head: [2]int{12, 10}, dir: "left", tail: [][2]int{{12, 12}, {12, 11}}}},
+		{"swap", snake{id: "b", head: [2]int{11, 10}, dir: "left", tail: [][2]int{{11, 12}, {11, 11}}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			game := newStepGame(t, true,
+				snake{id: "a", head: [2]int{10, 10}, dir: "right", tail: [][2]int{{8, 10}, {9, 10}}},
+				tc.b,
+			)
+			game.step([]string{"a", "b"})
+
+			checkPlayer(t, game, "a", true, "", 0)
+			checkPlayer(t, game, "b", true, "", 0)
+		})
+	}
+}
+
+func TestStepGhost(t *testing.T) {
+	game := newStepGame(t, false,
+		snake{id: "a", head: [2]int{10, 10}, dir: "down", ghost: true},
+		snake{id: "b", head: [2]int{12, 11}, dir: "right", tail: [][2]int{{10, 11}, {11, 11}}},
+	)
+	game.step([]string{"a", "b"})
+
+	checkPlayer(t, game, "a", false, "", 0)
+	checkPlayer(t, game, "b", false, "", 0)
+	if crd := game.State.Players["a"].Crd; crd != [2]int{10, 11} {
+		t.Errorf("ghost moved to %v, want %v", crd, [2]int{10, 11})
+	}
+}
+
+func TestStepGhostHead(t *testing.T) {
+	game := newStepGame(t, false,
+		snake{id: "a", head: [2]int{10, 10}, dir: "down", ghost: true},
+		snake{id: "b", head: [2]int{10, 11}, dir: "right"},
+	)
+	game.step([]string{"a"})
+
+	checkPlayer(t, game, "a", true, "b", 0)
+	checkPlayer(t, game, "b", false, "", 1)
+}
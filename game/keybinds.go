@@ -0,0 +1,265 @@
+package game
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Maps an action to all keys that trigger it.
+	//
+	// Keys are raw terminal input padded to 3 bytes, as read by `Game.Start`.
+	KeyBinds map[string][][]byte
+)
+
+const (
+	ActionQuit      string = "quit"
+	ActionPause     string = "pause"
+	ActionAutopilot string = "autopilot"
+	ActionMinimap   string = "minimap"
+	ActionUp        string = "up"
+	ActionRight     string = "right"
+	ActionDown      string = "down"
+	ActionLeft      string = "left"
+
+	// Max amount of players sharing one keyboard.
+	MaxLocalPlayers int = 3
+)
+
+var (
+	ErrKeyConflict   = errors.New("key is already bound to another action")
+	ErrKeyLast       = errors.New("action needs at least one key")
+	ErrKeyUnknown    = errors.New("unknown key name")
+	ErrActionUnknown = errors.New("unknown action")
+
+	// All rebindable actions in the order they are shown to the user.
+	//
+	// Direction actions of additional local players are prefixed, see `PlayerAction`.
+	KeyActions = []string{
+		ActionUp, ActionRight, ActionDown, ActionLeft,
+		PlayerAction(ActionUp, 1), PlayerAction(ActionRight, 1), PlayerAction(ActionDown, 1), PlayerAction(ActionLeft, 1),
+		PlayerAction(ActionUp, 2), PlayerAction(ActionRight, 2), PlayerAction(ActionDown, 2), PlayerAction(ActionLeft, 2),
+		ActionPause, ActionAutopilot, ActionMinimap, ActionQuit,
+	}
+
+	keyNames = map[string][]byte{
+		"ESC": {27, 0, 0}, "ENTER": {13, 0, 0}, "TAB": {9, 0, 0}, "SPACE": {32, 0, 0}, "BACKSPACE": {127, 0, 0},
+		"UP": {27, 91, 65}, "RIGHT": {27, 91, 67}, "DOWN": {27, 91, 66}, "LEFT": {27, 91, 68},
+	}
+)
+
+func DefaultKeyBinds() KeyBinds {
+	return KeyBinds{
+		ActionQuit:      {{3, 0, 0}, {4, 0, 0}, {113, 0, 0}},
+		ActionPause:     {{27, 0, 0}, {112, 0, 0}},
+		ActionAutopilot: {{9, 0, 0}},
+		ActionMinimap:   {{109, 0, 0}},
+		ActionUp:        {{119, 0, 0}},
+		ActionRight:     {{100, 0, 0}},
+		ActionDown:      {{115, 0, 0}},
+		ActionLeft:      {{97, 0, 0}},
+
+		PlayerAction(ActionUp, 1):    {{27, 91, 65}},
+		PlayerAction(ActionRight, 1): {{27, 91, 67}},
+		PlayerAction(ActionDown, 1):  {{27, 91, 66}},
+		PlayerAction(ActionLeft, 1):  {{27, 91, 68}},
+
+		PlayerAction(ActionUp, 2):    {{107, 0, 0}},
+		PlayerAction(ActionRight, 2): {{108, 0, 0}},
+		PlayerAction(ActionDown, 2):  {{106, 0, 0}},
+		PlayerAction(ActionLeft, 2):  {{104, 0, 0}},
+	}
+}
+
+// Get the action of direction `action` for local player `player`, starting at 0.
+func PlayerAction(action string, player int) string {
+	if player <= 0 {
+		return action
+	}
+	return "p" + strconv.Itoa(player+1) + "." + action
+}
+
+// Get a human readable name for `action`.
+func ActionName(action string) string {
+	player, action := SplitAction(action)
+	name := strings.ToUpper(action[:1]) + action[1:]
+	if player > 0 {
+		name = "P" + strconv.Itoa(player+1) + " " + name
+	}
+	return name
+}
+
+// Split `action` into the local player it belongs to and the unprefixed action.
+func SplitAction(action string) (player int, unprefixed string) {
+	prefix, unprefixed, ok := strings.Cut(action, ".")
+	if !ok || !strings.HasPrefix(prefix, "p") {
+		return 0, action
+	}
+	player, err := strconv.Atoi(prefix[1:])
+	if err != nil || player < 1 {
+		return 0, action
+	}
+	return player - 1, unprefixed
+}
+
+// Load key binds from a json file mapping actions to key names.
+//
+// Missing actions keep their default keys unless these are bound elsewhere, a missing file results in the defaults.
+func LoadKeyBinds(path string) (KeyBinds, error) {
+	kb := DefaultKeyBinds()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return kb, nil
+		}
+		return kb, err
+	}
+
+	named := map[string][]string{}
+	if err := json.Unmarshal(data, &named); err != nil {
+		return kb, err
+	}
+
+	loaded := KeyBinds{}
+	for action, names := range named {
+		if !slices.Contains(KeyActions, action) {
+			return kb, fmt.Errorf("%w: %v", ErrActionUnknown, action)
+		}
+		for _, name := range names {
+			key, err := ParseKey(name)
+			if err != nil {
+				return kb, err
+			}
+			if err := loaded.Bind(action, key); err != nil {
+				return kb, fmt.Errorf("%w: %v", err, name)
+			}
+		}
+	}
+
+	for _, action := range KeyActions {
+		if len(loaded[action]) > 0 {
+			continue
+		}
+		for _, key := range kb[action] {
+			if loaded.Action(key) == "" {
+				loaded[action] = append(loaded[action], key)
+			}
+		}
+	}
+
+	return loaded, nil
+}
+
+// Save key binds as a json file mapping actions to key names, creating its directory if needed.
+func (kb KeyBinds) Save(path string) error {
+	named := map[string][]string{}
+	for _, action := range KeyActions {
+		named[action] = kb.Names(action)
+	}
+
+	data, err := json.MarshalIndent(named, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Get the action bound to `key`, returns an empty string if `key` is not bound.
+func (kb KeyBinds) Action(key []byte) string {
+	for _, action := range KeyActions {
+		if slices.ContainsFunc(kb[action], func(k []byte) bool { return slices.Equal(k, key) }) {
+			return action
+		}
+	}
+	return ""
+}
+
+// Bind `key` to `action`, binding a key that is already bound to `action` is a noop.
+//
+// Returns `ErrKeyConflict` if `key` is bound to a different action.
+func (kb KeyBinds) Bind(action string, key []byte) error {
+	if !slices.Contains(KeyActions, action) {
+		return ErrActionUnknown
+	}
+	if bound := kb.Action(key); bound == action {
+		return nil
+	} else if bound != "" {
+		return ErrKeyConflict
+	}
+
+	kb[action] = append(kb[action], slices.Clone(key))
+	return nil
+}
+
+// Unbind `key` from `action`.
+//
+// Returns `ErrKeyLast` if `key` is the last key of `action`.
+func (kb KeyBinds) Unbind(action string, key []byte) error {
+	if len(kb[action]) <= 1 {
+		return ErrKeyLast
+	}
+
+	kb[action] = slices.DeleteFunc(kb[action], func(k []byte) bool { return slices.Equal(k, key) })
+	return nil
+}
+
+// Get the names of all keys bound to `action`.
+func (kb KeyBinds) Names(action string) []string {
+	names := []string{}
+	for _, key := range kb[action] {
+		names = append(names, KeyName(key))
+	}
+	return names
+}
+
+// Get a human readable name for `key`.
+func KeyName(key []byte) string {
+	for name, k := range keyNames {
+		if slices.Equal(k, key) {
+			return name
+		}
+	}
+
+	trimmed := bytes.TrimRight(key, "\x00")
+	if len(trimmed) == 1 && trimmed[0] > 32 && trimmed[0] < 127 {
+		return string(trimmed)
+	}
+	if len(trimmed) == 1 && trimmed[0] >= 1 && trimmed[0] <= 26 {
+		return "CTRL_" + string(rune('A'+trimmed[0]-1))
+	}
+	return "0x" + hex.EncodeToString(trimmed)
+}
+
+// Parse a name as returned by `KeyName` back into a key.
+func ParseKey(name string) ([]byte, error) {
+	if key, ok := keyNames[name]; ok {
+		return slices.Clone(key), nil
+	}
+
+	if len(name) == 1 && name[0] > 32 && name[0] < 127 {
+		return []byte{name[0], 0, 0}, nil
+	}
+	if c, ok := strings.CutPrefix(name, "CTRL_"); ok && len(c) == 1 && c[0] >= 'A' && c[0] <= 'Z' {
+		return []byte{c[0] - 'A' + 1, 0, 0}, nil
+	}
+	if h, ok := strings.CutPrefix(name, "0x"); ok {
+		key, err := hex.DecodeString(h)
+		if err == nil && len(key) > 0 && len(key) <= 3 {
+			return append(key, make([]byte, 3-len(key))...), nil
+		}
+	}
+
+	return []byte{}, fmt.Errorf("%w: %v", ErrKeyUnknown, name)
+}
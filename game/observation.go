@@ -0,0 +1,46 @@
+package game
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+type (
+	// Full view of the arena sent to bots every time their snake moved.
+	//
+	// Bots answer with `<Tick> <direction>` before `DeadlineMs` has passed, later answers and answers for other ticks are dropped.
+	Observation struct {
+		Tick          int
+		ClientId      string
+		DeadlineMs    int
+		Width, Height int
+		// Object per cell indexed as `Grid[y][x]`, see the `Obj*` constants.
+		Grid    [][]int
+		Players map[string]Player
+		PeaCrds [][2]int
+		Pickups []PlacedPickup
+	}
+)
+
+// Get the observation of player `id` for `tick`, answers are due within `deadline`.
+func (game *Game) Observe(id string, tick int, deadline time.Duration) Observation {
+	grid := make([][]int, len(game.Board.Rows))
+	for y, row := range game.Board.Rows {
+		grid[y] = make([]int, len(row))
+		for x, val := range row {
+			grid[y][x] = int(val)
+		}
+	}
+
+	return Observation{
+		Tick:       tick,
+		ClientId:   id,
+		DeadlineMs: int(deadline.Milliseconds()),
+		Width:      game.Board.CurX + 1, Height: game.Board.CurY + 1,
+		Grid:    grid,
+		Players: maps.Clone(game.State.Players),
+		PeaCrds: slices.Clone(game.State.PeaCrds),
+		Pickups: slices.Clone(game.State.Pickups),
+	}
+}
@@ -0,0 +1,249 @@
+package game
+
+import (
+	"errors"
+	"math/rand/v2"
+	"slices"
+)
+
+type (
+	// Collectible with an effect on the player picking it up.
+	Pickup struct {
+		Name string
+		Obj  uint8
+		// Color and 2 character glyph the pickup is drawn with.
+		Color, Glyph string
+		// 2 character glyph drawn in ASCII mode, defaults to `[]`.
+		ASCII string
+		// Seconds the effect lasts, 0 only calls `Apply`.
+		Duration int
+		// Chance of spawning relative to the weights of the other pickups.
+		Weight int
+		// Called once when picked up, optional.
+		Apply func(game *Game, id string)
+		// Called every player tick while the effect lasts, optional.
+		OnMove func(game *Game, id string)
+	}
+
+	PlacedPickup struct {
+		Crd [2]int
+		Obj uint8
+	}
+)
+
+const (
+	// Moves twice every player tick.
+	EffectSpeed string = "Speed"
+	// All other players only move every other player tick.
+	EffectSlow string = "Slow"
+	// Moves through bodies of other players and itself, heads still kill.
+	EffectGhost string = "Ghost"
+	// Loses half of its tail.
+	EffectShrink string = "Shrink"
+	// Pulls nearby peas towards its head.
+	EffectMagnet string = "Magnet"
+	// Earns double points for peas.
+	EffectDouble string = "Double"
+
+	// Distance peas are pulled from by `EffectMagnet`.
+	MagnetRange int = 6
+)
+
+var (
+	ErrPickupObj        = errors.New("pickup object should be free and between ObjCustom and ObjPlayer")
+	ErrPickupRegistered = errors.New("pickup is already registered")
+
+	// All registered pickups, new pickups should be registered before `NewGame` for them to be drawn.
+	Pickups = []Pickup{
+		{Name: EffectSpeed, Obj: ObjSpeed, Color: Green, Glyph: "▓▓", ASCII: ">>", Duration: 5, Weight: 3},
+		{Name: EffectSlow, Obj: ObjSlow, Color: Blue, Glyph: "▓▓", ASCII: "<<", Duration: 5, Weight: 2},
+		{Name: EffectGhost, Obj: ObjGhost, Color: White, Glyph: "░░", ASCII: "??", Duration: 5, Weight: 2},
+		{Name: EffectShrink, Obj: ObjShrink, Color: Red, Glyph: "▓▓", ASCII: "--", Duration: 0, Weight: 2, Apply: shrink},
+		{Name: EffectMagnet, Obj: ObjMagnet, Color: Magenta, Glyph: "▓▓", ASCII: "%%", Duration: 8, Weight: 2, OnMove: magnet},
+		{Name: EffectDouble, Obj: ObjDouble, Color: Yellow, Glyph: "▓▓", ASCII: "$$", Duration: 10, Weight: 3},
+	}
+)
+
+// Register a custom pickup, its `Obj` should be unique and from `ObjCustom` up to `ObjPlayer`.
+func RegisterPickup(pickup Pickup) error {
+	if pickup.Obj < ObjCustom || pickup.Obj >= ObjPlayer {
+		return ErrPickupObj
+	}
+	for _, p := range Pickups {
+		if p.Name == pickup.Name {
+			return ErrPickupRegistered
+		}
+		if p.Obj == pickup.Obj {
+			return ErrPickupObj
+		}
+	}
+	Pickups = append(Pickups, pickup)
+	return nil
+}
+
+// Get the registered pickup drawn as `obj`.
+func pickupByObj(obj uint8) (Pickup, bool) {
+	i := slices.IndexFunc(Pickups, func(p Pickup) bool { return p.Obj == obj })
+	if i == -1 {
+		return Pickup{}, false
+	}
+	return Pickups[i], true
+}
+
+// Get the registered pickup with effect `name`.
+func pickupByName(name string) (Pickup, bool) {
+	i := slices.IndexFunc(Pickups, func(p Pickup) bool { return p.Name == name })
+	if i == -1 {
+		return Pickup{}, false
+	}
+	return Pickups[i], true
+}
+
+// Check if player `id` has effect `name` active.
+func (game *Game) HasEffect(id, name string) bool {
+	return game.State.Players[id].Effects[name] > 0
+}
+
+// Check if player `id` is slowed down by another player.
+func (game *Game) isSlowed(id string) bool {
+	for otherId, playerState := range game.State.Players {
+		if otherId != id && !playerState.IsGameOver && playerState.Effects[EffectSlow] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Place a random pickup on an empty cell, chosen by weight.
+func (game *Game) SpawnPickup() {
+	total := 0
+	for _, p := range Pickups {
+		total += max(0, p.Weight)
+	}
+	if total == 0 {
+		return
+	}
+
+	pick, obj := rand.IntN(total), uint8(0)
+	for _, p := range Pickups {
+		if pick -= max(0, p.Weight); pick < 0 {
+			obj = p.Obj
+			break
+		}
+	}
+
+	for i := 1; i < 100; i++ {
+		cord := [2]int{rand.IntN(game.Board.CurX-1) + 1, rand.IntN(game.Board.CurY-1) + 1}
+		val, _ := game.Board.GetColRow(cord[0], cord[1])
+		if val == ObjEmpty {
+			game.State.Pickups = append(game.State.Pickups, PlacedPickup{Crd: cord, Obj: obj})
+			_ = game.Board.SetColRow(cord[0], cord[1], obj)
+			break
+		}
+	}
+}
+
+// Forget pickups that are no longer on the board and sometimes spawn a new one when below the limit.
+func (game *Game) UpdatePickups() {
+	game.State.Pickups = slices.DeleteFunc(game.State.Pickups, func(pickup PlacedPickup) bool {
+		val, err := game.Board.GetColRow(pickup.Crd[0], pickup.Crd[1])
+		return err != nil || val != pickup.Obj
+	})
+
+	if len(game.State.Pickups) < max(1, game.Config.PeaSpawnLimit/2) && rand.IntN(3) == 0 {
+		game.SpawnPickup()
+	}
+}
+
+// Give player `id` the effect of the pickup at `crd`.
+func (game *Game) collect(id string, crd [2]int, pickup Pickup) {
+	game.State.Pickups = slices.DeleteFunc(game.State.Pickups, func(p PlacedPickup) bool { return p.Crd == crd })
+
+	if pickup.Duration > 0 {
+		playerState := game.State.Players[id]
+		if playerState.Effects == nil {
+			playerState.Effects = map[string]int{}
+		}
+		playerState.Effects[pickup.Name] = pickup.Duration * game.Config.PlayerSpeed
+		game.State.Players[id] = playerState
+	}
+	if pickup.Apply != nil {
+		pickup.Apply(game, id)
+	}
+}
+
+// Run and count down the effects of all players in `ids` that are still alive.
+func (game *Game) updateEffects(ids []string) {
+	for _, id := range ids {
+		playerState, ok := game.State.Players[id]
+		if !ok || playerState.IsGameOver {
+			continue
+		}
+
+		for name, left := range playerState.Effects {
+			if pickup, ok := pickupByName(name); ok && pickup.OnMove != nil {
+				pickup.OnMove(game, id)
+			}
+			if left <= 1 {
+				delete(playerState.Effects, name)
+			} else {
+				playerState.Effects[name] = left - 1
+			}
+		}
+	}
+}
+
+// Draw all bodies again, cells shared with a ghost are cleared when either player moves away.
+func (game *Game) paintPlayers() {
+	for id, playerState := range game.State.Players {
+		if playerState.Decayed {
+			continue
+		}
+		for _, crd := range playerState.TailCrds {
+			_ = game.Board.SetColRow(crd[0], crd[1], game.playerObj(id))
+		}
+	}
+	for id, playerState := range game.State.Players {
+		if !playerState.Decayed {
+			_ = game.Board.SetColRow(playerState.Crd[0], playerState.Crd[1], game.playerObj(id))
+		}
+	}
+}
+
+func shrink(game *Game, id string) {
+	playerState := game.State.Players[id]
+
+	n := len(playerState.TailCrds) / 2
+	for _, crd := range playerState.TailCrds[:n] {
+		if val, err := game.Board.GetColRow(crd[0], crd[1]); err == nil && val >= ObjPlayer {
+			_ = game.Board.SetColRow(crd[0], crd[1], ObjEmpty)
+		}
+	}
+	playerState.TailCrds = slices.Clone(playerState.TailCrds[n:])
+	game.State.Players[id] = playerState
+}
+
+func magnet(game *Game, id string) {
+	head := game.State.Players[id].Crd
+
+	for i, pea := range game.State.PeaCrds {
+		dx, dy := head[0]-pea[0], head[1]-pea[1]
+		if dist := abs(dx) + abs(dy); dist <= 1 || dist > MagnetRange {
+			continue
+		}
+
+		next := pea
+		if abs(dx) >= abs(dy) {
+			next[0] += dx / abs(dx)
+		} else {
+			next[1] += dy / abs(dy)
+		}
+		if val, err := game.Board.GetColRow(next[0], next[1]); err != nil || val != ObjEmpty {
+			continue
+		}
+
+		_ = game.Board.SetColRow(pea[0], pea[1], ObjEmpty)
+		_ = game.Board.SetColRow(next[0], next[1], ObjPea)
+		game.State.PeaCrds[i] = next
+	}
+}
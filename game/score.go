@@ -0,0 +1,137 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	Score struct {
+		Peas, Kills, Milestones int
+		// Player moves survived and the whole seconds they took at the player speed.
+		Moves, Survival int
+		// Extra points from effects like `EffectDouble`.
+		Bonus  int
+		Points int
+	}
+)
+
+const (
+	PointsPea       int = 10
+	PointsKill      int = 50
+	PointsSecond    int = 1
+	PointsMilestone int = 25
+
+	// Length a snake has to grow by for every milestone.
+	MilestoneLength int = 10
+)
+
+// Recalculate the survival time and points at `speed` player moves per second.
+func (score *Score) tally(speed int) {
+	score.Survival = score.Moves / max(1, speed)
+	score.Points = score.Peas*PointsPea + score.Kills*PointsKill + score.Survival*PointsSecond + score.Milestones*PointsMilestone + score.Bonus
+}
+
+// Get the ids of all players sorted by points, highest first.
+func (game *Game) Ranking() []string {
+	ids := []string{}
+	for id := range game.State.Players {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		if diff := game.State.Players[b].Score.Points - game.State.Players[a].Score.Points; diff != 0 {
+			return diff
+		}
+		return strings.Compare(a, b)
+	})
+	return ids
+}
+
+// Get the name a player is shown with.
+func (game *Game) playerName(id string) string {
+	color := game.playerColor(id)
+	if game.Config.LocalPlayers > 1 {
+		if i, err := strconv.Atoi(id); err == nil {
+			return game.paint(color, "P"+strconv.Itoa(i+1))
+		}
+	} else if id == game.Config.ClientId {
+		return game.paint(color, "You")
+	}
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	return game.paint(color, id)
+}
+
+// Draw the scoreboard or the game over summary on top of the last drawn frame.
+func (game *Game) overlay() {
+	if game.isGameOver() {
+		game.summary()
+	} else if len(game.State.Players) > 1 {
+		game.scoreboard()
+	}
+}
+
+// Draw the points of the leading players in the top right corner, including the local players when they are not leading.
+func (game *Game) scoreboard() {
+	lines := []string{"Scores"}
+	for i, id := range game.Ranking() {
+		if i >= 5 && !slices.Contains(game.localIds(), id) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %6d", padAnsi(game.playerName(id), 12), game.State.Players[id].Score.Points))
+	}
+
+	width, height := game.Screen.TermSize()
+	offX, offY := game.Screen.Offset()
+	x := max(3, width-24)
+	for i, line := range lines {
+		if i+3 >= height {
+			break
+		}
+		fmt.Printf("\0337\033[%d;%dH %s \0338", offY+i+3, offX+x, padAnsi(line, 19))
+	}
+}
+
+// Draw the score breakdown of every player below the game over text.
+func (game *Game) summary() {
+	lines := []string{fmt.Sprintf("%-12s %5s %5s %6s %6s %6s", "Player", "Peas", "Kills", "Time", "Length", "Points")}
+	for _, id := range game.Ranking() {
+		playerState := game.State.Players[id]
+		lines = append(lines, fmt.Sprintf("%s %5d %5d %6s %6d %6d",
+			padAnsi(game.playerName(id), 12),
+			playerState.Score.Peas,
+			playerState.Score.Kills,
+			fmt.Sprintf("%02d:%02d", playerState.Score.Survival/60, playerState.Score.Survival%60),
+			len(playerState.TailCrds)+1,
+			playerState.Score.Points,
+		))
+	}
+
+	_, height := game.Screen.TermSize()
+	offX, offY := game.Screen.Offset()
+	for i, line := range lines {
+		if i+17 >= height {
+			break
+		}
+		fmt.Printf("\0337\033[%d;%dH %s \0338", offY+i+17, offX+5, padAnsi(line, 48))
+	}
+}
+
+// Pad `str` with spaces to `width` visible characters, ignoring color codes.
+func padAnsi(str string, width int) string {
+	visible, escaped := 0, false
+	for _, r := range str {
+		switch {
+		case r == '\033':
+			escaped = true
+		case escaped:
+			escaped = r != 'm'
+		default:
+			visible++
+		}
+	}
+	return str + strings.Repeat(" ", max(0, width-visible))
+}
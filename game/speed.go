@@ -0,0 +1,82 @@
+package game
+
+const (
+	// Every player moves at `PlayerSpeed` for the whole game.
+	SpeedFixed string = "fixed"
+	// Players speed up by 1 move per second every `SpeedStep` peas.
+	SpeedPeas string = "peas"
+	// Players speed up by 1 move per second every `SpeedStep` seconds survived.
+	SpeedTime string = "time"
+)
+
+var (
+	// All speed curves in the order they are shown to the user.
+	SpeedCurves = []string{SpeedFixed, SpeedPeas, SpeedTime}
+)
+
+// Get the moves per second a player with `score` has earned on the speed curve, never above `SpeedCap` or the TPS.
+func (game *Game) curveSpeed(score Score) int {
+	speed := game.Config.PlayerSpeed
+	switch game.Config.SpeedCurve {
+	case SpeedPeas:
+		speed += score.Peas / max(1, game.Config.SpeedStep)
+	case SpeedTime:
+		speed += score.Survival / max(1, game.Config.SpeedStep)
+	}
+	if game.Config.SpeedCap > 0 {
+		speed = min(speed, max(game.Config.SpeedCap, game.Config.PlayerSpeed))
+	}
+	return max(1, min(speed, game.Config.TargetTPS))
+}
+
+// Get the moves per second of player `id` including the effects of pickups.
+func (game *Game) SpeedOf(id string) int {
+	speed := game.State.Players[id].Speed
+	if speed <= 0 {
+		speed = game.Config.PlayerSpeed
+	}
+	if game.HasEffect(id, EffectSpeed) {
+		speed *= 2
+	}
+	if game.isSlowed(id) {
+		speed = max(1, speed/2)
+	}
+	return max(1, min(speed, game.Config.TargetTPS))
+}
+
+// Advance all players in `ids` by frame `frame` at `TargetTPS`, every player moves as soon as its speed added up to the TPS.
+//
+// Bots and the autopilot steer right before they move, a player tick is counted every `TargetTPS / PlayerSpeed` frames.
+// Returns the players that were due to move and if a player tick passed.
+func (game *Game) UpdateFrame(ids []string, frame int) (moved []string, ticked bool) {
+	due := []string{}
+	for _, id := range ids {
+		playerState, ok := game.State.Players[id]
+		if !ok || playerState.IsGameOver {
+			delete(game.progress, id)
+			continue
+		}
+
+		game.progress[id] += game.SpeedOf(id)
+		if game.progress[id] < game.Config.TargetTPS {
+			continue
+		}
+		game.progress[id] -= game.Config.TargetTPS
+		due = append(due, id)
+
+		if level, ok := game.Bots[id]; ok {
+			playerState.Dir = game.BotDir(id, level)
+		} else if game.Autopilot && id == game.Config.ClientId {
+			playerState.Dir = game.BotDir(id, BotHard)
+		}
+		game.State.Players[id] = playerState
+	}
+
+	game.step(due)
+
+	ticked = frame%max(1, game.Config.TargetTPS/game.Config.PlayerSpeed) == 0
+	if ticked {
+		game.tickPlayers(ids)
+	}
+	return due, ticked
+}
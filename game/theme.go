@@ -0,0 +1,256 @@
+package game
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"ASnake/screen"
+)
+
+type (
+	// Colors the game is drawn with, loaded from a theme file.
+	//
+	// Colors are one of `screen.ColorNames` or `#rrggbb`, which is reduced to the color depth of the terminal.
+	Theme struct {
+		Name string
+		// Background of the board, empty keeps the background of the terminal.
+		Background                          string
+		Wall, Portal, Pea, PlusOne, Warning string
+		// Empty cells of the minimap.
+		Minimap string
+		// Colors of pickups by name, pickups without a color keep their own.
+		Pickups map[string]string
+		// Colors of players in the order they are assigned, at most `PlayerColorSlots`.
+		Players []string
+		// Stats bar colors of values that need attention and of enabled modes like autopilot.
+		Alert, Highlight string
+	}
+
+	// 2 character glyphs of objects in a render mode.
+	glyphSet struct {
+		Empty, Wall, PlusOne, Warning, Pea, Portal string
+		// Snake bodies of local players, bodies of other players and heads.
+		Own, Other, Head string
+		// Empty cells of the minimap.
+		Minimap string
+	}
+)
+
+const (
+	// Name of the theme used until another one is applied.
+	ThemeDefault string = "classic"
+
+	// Colored blocks.
+	RenderBlocks string = "blocks"
+	// ASCII glyphs without colors, for terminals without UTF-8 or colors, screen readers and log captures.
+	RenderASCII string = "ascii"
+	// Colored half blocks, fitting twice as many rows and columns of cells in the terminal.
+	RenderHalf string = "half"
+)
+
+var (
+	ErrThemeUnknown  = errors.New("unknown theme")
+	ErrThemePlayers  = errors.New("theme should have between 1 and 16 player colors")
+	ErrRenderUnknown = errors.New("unknown render mode")
+
+	//go:embed themes/*.json
+	builtinThemes embed.FS
+
+	// All render modes in the order they are shown to the user.
+	RenderModes = []string{RenderBlocks, RenderASCII, RenderHalf}
+
+	renderGlyphs = map[string]glyphSet{
+		RenderBlocks: {Empty: "  ", Wall: "██", PlusOne: "██", Warning: "██", Pea: "██", Portal: "░░", Own: "██", Other: "▒▒", Head: "▓▓", Minimap: "▒▒"},
+		RenderASCII:  {Empty: "  ", Wall: "##", PlusOne: "++", Warning: "!!", Pea: "**", Portal: "()", Own: "OO", Other: "oo", Head: "@@", Minimap: ".."},
+	}
+)
+
+// Get the render mode to start with, ASCII when `NO_COLOR` is set.
+func DefaultRender() string {
+	if os.Getenv("NO_COLOR") != "" {
+		return RenderASCII
+	}
+	return RenderBlocks
+}
+
+// Get the names of the themes embedded in the binary, sorted with the default theme first.
+func BuiltinThemes() []string {
+	entries, _ := builtinThemes.ReadDir("themes")
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	slices.Sort(names)
+	if i := slices.Index(names, ThemeDefault); i > 0 {
+		names = append([]string{ThemeDefault}, slices.Delete(names, i, i+1)...)
+	}
+	return names
+}
+
+// Get a built-in theme by name or load a theme file from `name` otherwise.
+func ResolveTheme(name string) (*Theme, error) {
+	if data, err := builtinThemes.ReadFile(path.Join("themes", name+".json")); err == nil {
+		return ParseTheme(name, data)
+	}
+	if _, err := os.Stat(name); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrThemeUnknown, name)
+	}
+	return LoadTheme(name)
+}
+
+// Load a theme file holding a `Theme` as JSON.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTheme(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), data)
+}
+
+// Parse a theme, `name` is used when the theme does not name itself.
+func ParseTheme(name string, data []byte) (*Theme, error) {
+	theme := &Theme{}
+	if err := json.Unmarshal(data, theme); err != nil {
+		return nil, err
+	}
+	if theme.Name == "" {
+		theme.Name = name
+	}
+	return theme, theme.validate()
+}
+
+func (theme *Theme) validate() error {
+	if len(theme.Players) < 1 || len(theme.Players) > int(PlayerColorSlots) {
+		return ErrThemePlayers
+	}
+
+	colors := append([]string{theme.Wall, theme.Portal, theme.Pea, theme.PlusOne, theme.Warning, theme.Minimap, theme.Alert, theme.Highlight}, theme.Players...)
+	for _, color := range theme.Pickups {
+		colors = append(colors, color)
+	}
+	if _, err := screen.Background(theme.Background, screen.DepthTrue); err != nil {
+		return err
+	}
+	for _, color := range colors {
+		if _, err := screen.Color(color, screen.DepthTrue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Draw the game with `theme` from now on.
+func (game *Game) ApplyTheme(theme *Theme) {
+	game.Theme = theme
+
+	// Half blocks draw the board with `Screen.Palette`, the glyphs of blocks are kept for everything printed from `CharMap`.
+	glyphs, ok := renderGlyphs[game.Render]
+	if !ok {
+		glyphs = renderGlyphs[RenderBlocks]
+	}
+	bg := ""
+	if game.Render != RenderASCII {
+		bg, _ = screen.Background(theme.Background, game.ColorDepth)
+	}
+	cell := func(color, glyph string) []byte {
+		return []byte(game.paint(bg+color, glyph))
+	}
+
+	charMap := map[uint8][]byte{
+		ObjEmpty:   cell("", glyphs.Empty),
+		ObjWall:    cell(game.color(theme.Wall), glyphs.Wall),
+		ObjPlusOne: cell(game.color(theme.PlusOne), glyphs.PlusOne),
+		ObjWarning: cell(game.color(theme.Warning), glyphs.Warning),
+		ObjPea:     cell(game.color(theme.Pea), glyphs.Pea),
+		ObjPortal:  cell(game.color(theme.Portal), glyphs.Portal),
+	}
+	for i := range ObjBot - ObjPlayer + 1 {
+		charMap[ObjPlayer+i] = cell(game.color(theme.Players[int(i)%len(theme.Players)]), glyphs.Own)
+	}
+	for _, pickup := range Pickups {
+		glyph := pickup.Glyph
+		if game.Render == RenderASCII {
+			glyph = cmp.Or(pickup.ASCII, "[]")
+		}
+		charMap[pickup.Obj] = cell(game.pickupColor(pickup), glyph)
+	}
+	for i, color := range theme.Players {
+		charMap[ObjSnakeOwn+uint8(i)] = cell(game.color(color), glyphs.Own)
+		charMap[ObjSnakeOther+uint8(i)] = cell(game.color(color), glyphs.Other)
+		charMap[ObjSnakeHead+uint8(i)] = cell(game.color(color), glyphs.Head)
+	}
+
+	game.Screen.CharMap = charMap
+	game.Screen.MinimapEmpty = cell(game.color(theme.Minimap), glyphs.Minimap)
+
+	palette := map[uint8]string{
+		ObjEmpty:   theme.Background,
+		ObjWall:    theme.Wall,
+		ObjPlusOne: theme.PlusOne,
+		ObjWarning: theme.Warning,
+		ObjPea:     theme.Pea,
+		ObjPortal:  theme.Portal,
+	}
+	for i := range ObjBot - ObjPlayer + 1 {
+		palette[ObjPlayer+i] = theme.Players[int(i)%len(theme.Players)]
+	}
+	for _, pickup := range Pickups {
+		palette[pickup.Obj] = cmp.Or(theme.Pickups[pickup.Name], theme.Highlight)
+	}
+	for i, color := range theme.Players {
+		palette[ObjSnakeOwn+uint8(i)] = color
+		palette[ObjSnakeOther+uint8(i)] = color
+		palette[ObjSnakeHead+uint8(i)] = color
+	}
+
+	game.Screen.HalfBlock = game.Render == RenderHalf
+	game.Screen.Palette = palette
+	game.Screen.MinimapEmptyColor = theme.Minimap
+	game.Screen.ColorDepth = game.ColorDepth
+}
+
+// Switch to render mode `mode`, keeping the current theme.
+func (game *Game) SetRender(mode string) error {
+	if !slices.Contains(RenderModes, mode) {
+		return fmt.Errorf("%w: %v", ErrRenderUnknown, mode)
+	}
+	game.Render = mode
+	game.ApplyTheme(game.Theme)
+	return nil
+}
+
+// Get the escape code of the theme color `color` at the color depth of the terminal, empty when drawn without colors.
+func (game *Game) color(color string) string {
+	if game.Render == RenderASCII {
+		return ""
+	}
+	code, _ := screen.Color(color, game.ColorDepth)
+	return code
+}
+
+// Get the escape code of the color `pickup` is drawn with.
+func (game *Game) pickupColor(pickup Pickup) string {
+	if game.Render == RenderASCII {
+		return ""
+	}
+	if color, ok := game.Theme.Pickups[pickup.Name]; ok {
+		return game.color(color)
+	}
+	return pickup.Color
+}
+
+// Get `text` in the escape code `color` followed by a reset, without any escape codes when `color` is empty.
+func (game *Game) paint(color, text string) string {
+	if color == "" {
+		return text
+	}
+	return color + text + Reset
+}
@@ -0,0 +1,376 @@
+// Package gym exposes a headless game as a reinforcement learning environment over json lines.
+//
+// Every request line is answered by exactly one response line:
+//
+//	{"cmd": "reset", "config": {"width": 20, "height": 20, "encoding": "features"}}
+//	{"cmd": "step", "action": "up"}
+//	{"cmd": "close"}
+package gym
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ASnake/game"
+)
+
+type (
+	Rewards struct {
+		// Reward for every pea eaten.
+		Pea float64 `json:"pea"`
+		// Reward when the agent dies.
+		Death float64 `json:"death"`
+		// Reward for every step survived.
+		Step float64 `json:"step"`
+		// Reward for every step moving closer to the nearest pea, negated when moving away.
+		Closer float64 `json:"closer"`
+	}
+
+	Config struct {
+		// Size of the arena including the border.
+		Width  int `json:"width"`
+		Height int `json:"height"`
+		// Wall mode, see `game.WallModes`.
+		Walls string `json:"walls"`
+		// Observation encoding, either `grid` or `features`.
+		Encoding string `json:"encoding"`
+		// Computer controlled opponents and their level, see `game.BotLevels`.
+		Bots     int    `json:"bots"`
+		BotLevel string `json:"botLevel"`
+		// Steps before an episode is truncated.
+		MaxSteps int `json:"maxSteps"`
+		// Reward shaping, nil when omitted so all zero rewards can still be asked for.
+		Rewards *Rewards `json:"rewards"`
+	}
+
+	Request struct {
+		Cmd    string          `json:"cmd"`
+		Config *Config         `json:"config"`
+		Action json.RawMessage `json:"action"`
+	}
+
+	Response struct {
+		Observation any            `json:"observation,omitempty"`
+		Reward      float64        `json:"reward"`
+		Done        bool           `json:"done"`
+		Truncated   bool           `json:"truncated"`
+		Info        map[string]any `json:"info,omitempty"`
+		Error       string         `json:"error,omitempty"`
+	}
+
+	Env struct {
+		Config Config
+		Game   *game.Game
+		steps  int
+		// The episode ended by death or truncation, steps need a reset first.
+		done bool
+	}
+)
+
+const (
+	EncodingGrid     string = "grid"
+	EncodingFeatures string = "features"
+
+	// Id of the agent in `Env.Game.State.Players`.
+	AgentId string = "agent"
+
+	// Largest width and height of the arena.
+	MaxSize int = 256
+	// Smallest width and height of an arena with bots, every bot needs 2 free cells around it to spawn.
+	MinBotSize int = 12
+)
+
+var (
+	ErrNotReset        = errors.New("environment needs a reset")
+	ErrEncodingUnknown = errors.New("unknown observation encoding")
+	ErrActionInvalid   = errors.New("action should be 0-3 or one of up, right, down, left")
+	ErrCmdUnknown      = errors.New("unknown cmd")
+	ErrWallModeUnknown = errors.New("unknown wall mode")
+	ErrSizeTooLarge    = errors.New("arena should be at most 256x256")
+	ErrSizeBots        = errors.New("arena with bots should be at least 12x12")
+
+	DefaultConfig = Config{
+		Width: 20, Height: 20,
+		Walls:    game.WallsWrap,
+		Encoding: EncodingFeatures,
+		Bots:     0, BotLevel: "Medium",
+		MaxSteps: 1000,
+		Rewards:  &Rewards{Pea: 1, Death: -1, Step: 0, Closer: 0},
+	}
+)
+
+func NewEnv() *Env {
+	return &Env{Config: DefaultConfig}
+}
+
+// Start a new episode using `cfg`, omitted fields use `DefaultConfig`.
+func (env *Env) Reset(cfg Config) (Response, error) {
+	if cfg.Width <= 0 {
+		cfg.Width = DefaultConfig.Width
+	}
+	if cfg.Height <= 0 {
+		cfg.Height = DefaultConfig.Height
+	}
+	if cfg.Width < game.ArenaMinSize || cfg.Height < game.ArenaMinSize {
+		return Response{}, fmt.Errorf("%w: %vx%v", game.ErrArenaSize, cfg.Width, cfg.Height)
+	}
+	if cfg.Width > MaxSize || cfg.Height > MaxSize {
+		return Response{}, fmt.Errorf("%w: %vx%v", ErrSizeTooLarge, cfg.Width, cfg.Height)
+	}
+	if cfg.Bots > 0 && (cfg.Width < MinBotSize || cfg.Height < MinBotSize) {
+		return Response{}, fmt.Errorf("%w: %vx%v", ErrSizeBots, cfg.Width, cfg.Height)
+	}
+	if cfg.Walls == "" {
+		cfg.Walls = DefaultConfig.Walls
+	} else if !slices.Contains(game.WallModes, cfg.Walls) {
+		return Response{}, fmt.Errorf("%w: %v", ErrWallModeUnknown, cfg.Walls)
+	}
+	if cfg.Encoding == "" {
+		cfg.Encoding = DefaultConfig.Encoding
+	} else if cfg.Encoding != EncodingGrid && cfg.Encoding != EncodingFeatures {
+		return Response{}, fmt.Errorf("%w: %v", ErrEncodingUnknown, cfg.Encoding)
+	}
+	if cfg.BotLevel == "" {
+		cfg.BotLevel = DefaultConfig.BotLevel
+	}
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = DefaultConfig.MaxSteps
+	}
+	if cfg.Rewards == nil {
+		rewards := *DefaultConfig.Rewards
+		cfg.Rewards = &rewards
+	}
+
+	botLevel, err := game.ParseBotLevel(cfg.BotLevel)
+	if err != nil {
+		return Response{}, err
+	}
+
+	gm, err := game.NewGame(true)
+	if err != nil {
+		return Response{}, err
+	}
+	gm.Config.WallMode = cfg.Walls
+	gm.Board.Resize(cfg.Width, cfg.Height)
+	gm.DrawBorder()
+
+	gm.State.Players[AgentId] = game.Player{
+		Crd: [2]int{int(gm.Board.CurX / 2), int(gm.Board.CurY / 2)},
+		Dir: "right", CurDir: "right",
+		TailCrds: [][2]int{},
+	}
+	_ = gm.Board.SetColRow(gm.State.Players[AgentId].Crd[0], gm.State.Players[AgentId].Crd[1], game.ObjPlayer)
+
+	for range cfg.Bots {
+		if _, err := gm.AddBot(botLevel); err != nil {
+			return Response{}, err
+		}
+	}
+	for i := 0; i < gm.Config.PeaStartCount; i++ {
+		gm.SpawnPea()
+	}
+
+	env.Config, env.Game, env.steps, env.done = cfg, gm, 0, false
+	return env.response(0, false, false), nil
+}
+
+// Move the agent one cell in `action`, reversing into the own tail is ignored like for human players.
+func (env *Env) Step(action string) (Response, error) {
+	if env.Game == nil || env.done {
+		return Response{}, ErrNotReset
+	}
+	if !slices.Contains(game.Dirs, action) {
+		return Response{}, ErrActionInvalid
+	}
+
+	gm := env.Game
+	env.steps++
+
+	playerState := gm.State.Players[AgentId]
+	if action != game.OppositeDir(playerState.CurDir) {
+		playerState.Dir = action
+	}
+	gm.State.Players[AgentId] = playerState
+
+	oldDist := env.peaDist(playerState.Crd)
+	oldLen := len(playerState.TailCrds)
+
+	gm.UpdateBots()
+	gm.UpdatePlayers(append([]string{AgentId}, slices.Collect(maps.Keys(gm.Bots))...))
+	if env.steps%gm.PlayerMovesPerPea() == 0 {
+		gm.UpdatePeas()
+	}
+
+	playerState = gm.State.Players[AgentId]
+	reward := env.Config.Rewards.Step
+	if playerState.IsGameOver {
+		reward += env.Config.Rewards.Death
+	} else if grown := len(playerState.TailCrds) - oldLen; grown > 0 {
+		reward += env.Config.Rewards.Pea * float64(grown)
+	} else if newDist := env.peaDist(playerState.Crd); oldDist >= 0 && newDist >= 0 {
+		if newDist < oldDist {
+			reward += env.Config.Rewards.Closer
+		} else if newDist > oldDist {
+			reward -= env.Config.Rewards.Closer
+		}
+	}
+
+	res := env.response(reward, playerState.IsGameOver, env.steps >= env.Config.MaxSteps)
+	env.done = res.Done
+	return res, nil
+}
+
+func (env *Env) response(reward float64, done, truncated bool) Response {
+	playerState := env.Game.State.Players[AgentId]
+	return Response{
+		Observation: env.observe(),
+		Reward:      reward,
+		Done:        done || truncated,
+		Truncated:   truncated && !done,
+		Info: map[string]any{
+			"steps":  env.steps,
+			"length": len(playerState.TailCrds) + 1,
+			"crd":    playerState.Crd,
+			"dir":    playerState.CurDir,
+		},
+	}
+}
+
+// Get the observation in the configured encoding.
+//
+// `grid` is the arena as `Obj*` values indexed as `[y][x]`.
+//
+// `features` is a vector of 12 values: danger up, right, down, left; current direction one-hot up, right, down, left; nearest pea is up, right, down, left.
+func (env *Env) observe() any {
+	if env.Config.Encoding == EncodingGrid {
+		return env.Game.Observe(AgentId, env.steps, 0).Grid
+	}
+
+	gm := env.Game
+	playerState := gm.State.Players[AgentId]
+	features := []float64{}
+
+	for _, dir := range game.Dirs {
+		val, err := gm.Board.GetColRow(gm.NextCrd(playerState.Crd, dir)[0], gm.NextCrd(playerState.Crd, dir)[1])
+		features = append(features, boolFloat(err != nil || game.IsDeadly(val)))
+	}
+	for _, dir := range game.Dirs {
+		features = append(features, boolFloat(playerState.CurDir == dir))
+	}
+
+	pea, ok := env.nearestPea(playerState.Crd)
+	features = append(features,
+		boolFloat(ok && pea[1] < playerState.Crd[1]),
+		boolFloat(ok && pea[0] > playerState.Crd[0]),
+		boolFloat(ok && pea[1] > playerState.Crd[1]),
+		boolFloat(ok && pea[0] < playerState.Crd[0]),
+	)
+	return features
+}
+
+func (env *Env) nearestPea(crd [2]int) ([2]int, bool) {
+	best, bestDist := [2]int{}, -1
+	for _, pea := range env.Game.State.PeaCrds {
+		if dist := manhattan(crd, pea); bestDist == -1 || dist < bestDist {
+			best, bestDist = pea, dist
+		}
+	}
+	return best, bestDist != -1
+}
+
+func (env *Env) peaDist(crd [2]int) int {
+	pea, ok := env.nearestPea(crd)
+	if !ok {
+		return -1
+	}
+	return manhattan(crd, pea)
+}
+
+// Parse an action given as direction name or as index into `game.Dirs`.
+func ParseAction(raw json.RawMessage) (string, error) {
+	str := ""
+	if err := json.Unmarshal(raw, &str); err == nil {
+		str = strings.ToLower(str)
+		if slices.Contains(game.Dirs, str) {
+			return str, nil
+		}
+		raw = json.RawMessage(str)
+	}
+
+	i, err := strconv.Atoi(string(raw))
+	if err != nil || i < 0 || i >= len(game.Dirs) {
+		return "", ErrActionInvalid
+	}
+	return game.Dirs[i], nil
+}
+
+// Answer requests from `r` on `w` until `r` is exhausted or a `close` request is received.
+func Serve(r io.Reader, w io.Writer) error {
+	env := NewEnv()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	encoder := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		req := Request{}
+		res, err := Response{}, json.Unmarshal(scanner.Bytes(), &req)
+		if err == nil {
+			switch req.Cmd {
+			case "reset":
+				cfg := DefaultConfig
+				if req.Config != nil {
+					cfg = *req.Config
+				}
+				res, err = env.Reset(cfg)
+			case "step":
+				action, actErr := ParseAction(req.Action)
+				if actErr != nil {
+					err = actErr
+					break
+				}
+				res, err = env.Step(action)
+			case "close":
+				return encoder.Encode(Response{Done: true})
+			default:
+				err = fmt.Errorf("%w: %v", ErrCmdUnknown, req.Cmd)
+			}
+		}
+
+		if err != nil {
+			res = Response{Error: err.Error()}
+		}
+		if err := encoder.Encode(res); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func manhattan(a, b [2]int) int {
+	x, y := a[0]-b[0], a[1]-b[1]
+	if x < 0 {
+		x = -x
+	}
+	if y < 0 {
+		y = -y
+	}
+	return x + y
+}
@@ -1,36 +1,133 @@
 package main
 
 import (
-	"bufio"
-	"encoding/json"
+	"cmp"
+	"errors"
 	"fmt"
-	"net"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"ASnake/client"
 	"ASnake/game"
+	"ASnake/gym"
 	"ASnake/server"
+	"ASnake/tournament"
 
 	"github.com/HandyGold75/GOLib/argp"
 	"github.com/HandyGold75/GOLib/tui"
 )
 
 var args = argp.ParseArgs(struct {
-	Help       bool   `switch:"h,-help" opts:"help"        help:"Another game of Snake."`
-	Server     bool   `switch:"s,-server"                  help:"Start as a server instace."`
-	IP         string `switch:"i,-ip" default:"0.0.0.0"    help:"Listen on this ip when started as server."`
-	Port       uint16 `switch:"p,-port" default:"17530"    help:"Listen on this port when started as server."`
-	MaxClients int    `switch:"m,-max-clients" default:"4" help:"Max amount of clients per pool."`
+	Help        bool   `switch:"h,-help" opts:"help"        help:"Another game of Snake."`
+	Server      bool   `switch:"s,-server"                  help:"Start as a server instace."`
+	IP          string `switch:"i,-ip" default:"0.0.0.0"    help:"Listen on this ip when started as server."`
+	Port        uint16 `switch:"p,-port" default:"17530"    help:"Listen on this port when started as server."`
+	MaxClients  int    `switch:"m,-max-clients" default:"4" help:"Max amount of clients per pool."`
+	Bots        string `switch:"b,-bots"                    help:"Fill empty pool slots with bots of this level (random, easy, medium, hard)."`
+	Walls       string `switch:"w,-walls" default:"wrap"    help:"Wall behavior of pools (wrap, solid, horizontal, vertical)."`
+	Arena       string `switch:"f,-arena"                   help:"Built-in arena or map file to play on (box, cross, rooms or a path)."`
+	Theme       string `switch:"o,-theme"                   help:"Built-in theme or theme file to draw with (classic, colorblind, high-contrast, phosphor or a path)."`
+	ShorterDies bool   `switch:"d,-shorter-dies"            help:"Only the shorter snake dies in head on collisions."`
+	DeadBodies  string `switch:"x,-dead-bodies" default:"stay" help:"What happens to bodies of dead snakes in pools (stay, peas, fade)."`
+	DecayDelay  int    `switch:"z,-decay-delay" default:"3" help:"Seconds before dead bodies turn into peas or fade."`
+	Pickups     bool   `switch:"u,-pickups"                 help:"Spawn pickups with special effects in pools."`
+	SpeedCurve  string `switch:"v,-speed-curve" default:"fixed" help:"How snakes in pools speed up (fixed, peas, time)."`
+	SpeedStep   int    `switch:"n,-speed-step" default:"5"  help:"Peas or seconds per speed up."`
+	SpeedCap    int    `switch:"a,-speed-cap" default:"15"  help:"Max moves per second of sped up snakes."`
+	Rounds      int    `switch:"r,-rounds" default:"10"     help:"Matches every pair of bots plays in a tournament."`
+	MaxTicks    int    `switch:"t,-max-ticks" default:"5000" help:"Player ticks before a tournament match is decided by length."`
+	Entrants    string `switch:"e,-entrants"                help:"Comma separated bots entering a tournament, defaults to all registered bots."`
+	Command     string `switch:"c,-command"  opts:"posistional" help:"Run a command instead of the game, one of: tournament, gym."`
 }{})
 
-func mainMenu(gm *game.Game) (mode string, ipStr string, err error) {
+type (
+	// Menu item that captures the next key press and toggles it on `action`.
+	keyBindItem struct {
+		mm     *tui.MainMenu
+		name   string
+		action string
+		binds  game.KeyBinds
+		// Key binds are saved here after every change.
+		path    string
+		editing bool
+	}
+)
+
+func (k *keyBindItem) String() string { return k.name }
+func (k *keyBindItem) Value() string  { return strings.Join(k.binds.Names(k.action), " ") }
+func (k *keyBindItem) Type() string   { return "text" }
+func (k *keyBindItem) Editing() bool  { return k.editing }
+
+func (k *keyBindItem) Enter() error {
+	k.editing = true
+	k.mm.StatusLine("Press a key to bind or unbind it for " + k.name)
+
+	in := make([]byte, 3)
+	_, err := os.Stdin.Read(in)
+	k.editing = false
+	if err != nil {
+		return err
+	}
+
+	name := game.KeyName(in)
+	switch bound := k.binds.Action(in); bound {
+	case k.action:
+		if err := k.binds.Unbind(k.action, in); err != nil {
+			k.mm.StatusLine("Unable to unbind " + name + ": " + err.Error())
+			return nil
+		}
+		k.save("Unbound " + name + " from " + k.name)
+	case "":
+		_ = k.binds.Bind(k.action, in)
+		k.save("Bound " + name + " to " + k.name)
+	default:
+		k.mm.StatusLine("Unable to bind " + name + ": already bound to " + bound)
+	}
+	return nil
+}
+
+// Save the key binds after a change and show `msg` on the status line, along with the error if saving failed.
+func (k *keyBindItem) save(msg string) {
+	if err := k.binds.Save(k.path); err != nil {
+		msg += ", unable to save key binds: " + err.Error()
+	}
+	k.mm.StatusLine(msg)
+}
+
+// Get the path of a config file in the ASnake directory of the user config directory.
+func configPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ASnake", name), nil
+}
+
+// Run the main menu, `target` is the address to connect to for multiplayer or the map file to open in the editor.
+//
+// Changed key binds are saved to `keyBindsPath`, `status` is shown on the status line until something else is.
+func mainMenu(gm *game.Game, keyBindsPath, status string) (mode string, target string, err error) {
 	mode = ""
 
 	tui.Defaults.Align = tui.AlignLeft
-	mm := tui.NewMenuBulky("ASnake")
+	if gm.Render == game.RenderASCII {
+		tui.Defaults.Color, tui.Defaults.AccentColor, tui.Defaults.SelectColor, tui.Defaults.ValueColor = "", "", "", ""
+	}
+	mm, md, err := newMenuDemo("ASnake", time.Second*30)
+	if err != nil {
+		return mode, "", err
+	}
+	if status != "" {
+		mm.StatusLine(status)
+	}
 
 	sp := mm.Menu.NewMenu("SinglePlayer")
 	sp.NewAction("Start", func() { mode = "singleplayer" })
+	spPlayers := sp.NewDigit("Players", 1, 1, game.MaxLocalPlayers)
 	spLockFPSToTPS := sp.NewList("Low Performance", []string{"No", "Yes"})
 	spTargetTPS := sp.NewDigit("Target TPS", 30, 0, 99999)
 	spTargetFPS := sp.NewDigit("Target FPS", 60, 0, 99999)
@@ -38,17 +135,82 @@ func mainMenu(gm *game.Game) (mode string, ipStr string, err error) {
 	spPeaSpawnDelay := sp.NewDigit("Spawn Delay", 5, 0, 99999)
 	spPeaSpawnLimit := sp.NewDigit("Spawn Limit", 3, 0, 99999)
 	spPeaStartCount := sp.NewDigit("Spawn Count", 1, 0, 99999)
+	spWalls := sp.NewList("Walls", game.WallModes)
+	arenas := append([]string{game.ArenaNone}, game.BuiltinArenas()...)
+	if args.Arena != "" {
+		arenas = append([]string{args.Arena}, slices.DeleteFunc(arenas, func(name string) bool { return name == args.Arena })...)
+	}
+	spArena := sp.NewList("Arena", arenas)
+	spShorterDies := sp.NewList("Shorter Dies", []string{"No", "Yes"})
+	spDeadBodies := sp.NewList("Dead Bodies", game.BodyModes)
+	spDecayDelay := sp.NewDigit("Decay Delay", 3, 0, 99999)
+	spPickups := sp.NewList("Pickups", []string{"No", "Yes"})
+	spSpeedCurve := sp.NewList("Speed Curve", game.SpeedCurves)
+	spSpeedStep := sp.NewDigit("Speed Step", 5, 1, 99999)
+	spSpeedCap := sp.NewDigit("Speed Cap", 15, 0, 99999)
+	spBots := sp.NewDigit("Bots", 0, 0, 99)
+	spBotLevel := sp.NewList("Bot Level", game.BotLevels)
 
 	mp := mm.Menu.NewMenu("MultiPlayer")
 	mp.NewAction("Connect", func() { mode = "multiplayer" })
 	mpIP := mp.NewIPv4("IP", "127.0.0.1")
 	mpPort := mp.NewDigit("Port", 17530, 0, 65535)
 
-	if err := mm.Run(); err != nil {
+	ed := mm.Menu.NewMenu("Editor")
+	ed.NewAction("Open", func() { mode = "editor" })
+	edFile := ed.NewText("File", tui.GeneralCharSet, cmp.Or(args.Arena, "arena.txt"))
+
+	ds := mm.Menu.NewMenu("Display")
+	themes := game.BuiltinThemes()
+	if args.Theme != "" {
+		themes = append([]string{args.Theme}, slices.DeleteFunc(themes, func(name string) bool { return name == args.Theme })...)
+	}
+	dsTheme := ds.NewList("Theme", themes)
+	renders := append([]string{gm.Render}, slices.DeleteFunc(slices.Clone(game.RenderModes), func(mode string) bool { return mode == gm.Render })...)
+	dsRender := ds.NewList("Render", renders)
+	dsResize := ds.NewList("Resize", game.ResizePolicies)
+
+	ctrl := mm.Menu.NewMenu("Controls")
+	for _, action := range game.KeyActions {
+		ctrl.Items = append(ctrl.Items, &keyBindItem{
+			mm:     mm,
+			name:   game.ActionName(action),
+			action: action,
+			binds:  gm.KeyBinds,
+			path:   keyBindsPath,
+		})
+	}
+
+	err = mm.Run()
+	_ = md.Close()
+	if err != nil {
+		return mode, "", err
+	}
+
+	theme, err := game.ResolveTheme(dsTheme.Value())
+	if err != nil {
+		return mode, "", err
+	}
+	gm.ApplyTheme(theme)
+	if err := gm.SetRender(dsRender.Value()); err != nil {
 		return mode, "", err
 	}
 
+	gm.Config.ResizePolicy = dsResize.Value()
 	gm.Config.LockFPSToTPS = spLockFPSToTPS.Value() == "Yes"
+	gm.Config.ShorterDies = spShorterDies.Value() == "Yes"
+	gm.Config.DeadBodies = spDeadBodies.Value()
+	gm.Config.Pickups = spPickups.Value() == "Yes"
+	gm.Config.SpeedCurve = spSpeedCurve.Value()
+	if gm.Config.SpeedStep, err = strconv.Atoi(spSpeedStep.Value()); err != nil {
+		return mode, "", err
+	}
+	if gm.Config.SpeedCap, err = strconv.Atoi(spSpeedCap.Value()); err != nil {
+		return mode, "", err
+	}
+	if gm.Config.DecayDelay, err = strconv.Atoi(spDecayDelay.Value()); err != nil {
+		return mode, "", err
+	}
 	if gm.Config.TargetTPS, err = strconv.Atoi(spTargetTPS.Value()); err != nil {
 		return mode, "", err
 	}
@@ -67,83 +229,77 @@ func mainMenu(gm *game.Game) (mode string, ipStr string, err error) {
 	if gm.Config.PeaStartCount, err = strconv.Atoi(spPeaStartCount.Value()); err != nil {
 		return mode, "", err
 	}
-	return mode, fmt.Sprintf("%v:%v", mpIP.Value(), mpPort.Value()), nil
-}
-
-func connect(gm *game.Game, ip string) error {
-	fmt.Print("\r\033[0JConnecting")
-
-	tcp, err := net.ResolveTCPAddr("tcp", ip)
-	if err != nil {
-		fmt.Print("\r\033[0JFailed\r\n")
-		return err
+	if mode == "singleplayer" {
+		gm.Config.WallMode = spWalls.Value()
+		gm.DrawBorder()
 	}
-
-	conn, err := net.DialTCP("tcp", nil, tcp)
-	if err != nil {
-		fmt.Print("\r\033[0JFailed\r\n")
-		return err
+	if mode == "singleplayer" && spArena.Value() != game.ArenaNone {
+		arena, err := game.ResolveArena(spArena.Value())
+		if err != nil {
+			return mode, "", err
+		}
+		gm.LoadArena(arena)
+	}
+	if players, err := strconv.Atoi(spPlayers.Value()); err != nil {
+		return mode, "", err
+	} else if mode == "singleplayer" {
+		gm.SetLocalPlayers(players)
 	}
 
-	_, err = conn.Write([]byte("Join\n"))
+	botLevel, err := game.ParseBotLevel(spBotLevel.Value())
 	if err != nil {
-		fmt.Print("\r\033[0JFailed\r\n")
-		_ = conn.Close()
-		return err
+		return mode, "", err
 	}
+	if bots, err := strconv.Atoi(spBots.Value()); err != nil {
+		return mode, "", err
+	} else if mode == "singleplayer" {
+		for range bots {
+			if _, err := gm.AddBot(botLevel); err != nil {
+				return mode, "", err
+			}
+		}
+	}
+	if mode == "editor" {
+		return mode, edFile.Value(), nil
+	}
+	return mode, fmt.Sprintf("%v:%v", mpIP.Value(), mpPort.Value()), nil
+}
 
-	reader := bufio.NewReader(conn)
+func connect(gm *game.Game, ip string) error {
+	fmt.Print("\r\033[0JConnecting")
 
-	msg, err := reader.ReadString('\n')
+	cl, err := client.Dial(ip, client.ModePlayer)
 	if err != nil {
+		if errors.Is(err, client.ErrRejected) {
+			fmt.Print("\r\033[0JRejected\r\n")
+			return nil
+		}
 		fmt.Print("\r\033[0JFailed\r\n")
-		_ = conn.Close()
 		return err
 	}
-	msg = strings.ReplaceAll(msg, "\n", "")
-
-	if msg != "Accept" {
-		fmt.Print("\r\033[0J" + msg + "\r\n")
-		return conn.Close()
-	}
 
-	gm.Config.Connection = conn
+	gm.Config.Connection = cl.Conn
 
 	fmt.Print("\r\033[0JJoined\r")
 
-	readerConn := bufio.NewReader(gm.Config.Connection)
-	msg, err = readerConn.ReadString('\n')
+	update, err := cl.WaitStart(func() { fmt.Print("\r\033[0JWaiting for players\r") })
 	if err != nil {
 		fmt.Print("\r\033[0JFailed\r\n")
 		return err
 	}
-	msg = strings.ReplaceAll(msg, "\n", "")
-
-	for msg == "waiting" {
-		fmt.Print("\r\033[0JWaiting for players\r")
-
-		msg, err = readerConn.ReadString('\n')
-		if err != nil {
-			fmt.Print("\r\033[0JFailed\r\n")
-			return err
-		}
-		msg = strings.ReplaceAll(msg, "\n", "")
-	}
 
 	fmt.Print("\r\033[0JStarting\r\n")
 
-	update := game.FirstUpdatePacket{}
-	err = json.Unmarshal([]byte(msg), &update)
-	if err != nil {
-		fmt.Print("\r\033[0JFailed\r\n")
-		return err
-	}
-
 	gm.Config.ClientId = update.ClientId
 	gm.StartTime = update.StartTime
-	gm.Screen.MaxX, gm.Screen.MaxY = update.MaxX, update.MaxY
+	gm.Board.Resize(update.MaxX, update.MaxY)
+	gm.Config.WallMode = update.WallMode
+	if update.Arena != nil {
+		gm.LoadArena(update.Arena)
+	}
 	gm.State.Players = update.State.Players
 	gm.State.PeaCrds = update.State.PeaCrds
+	gm.State.Pickups = update.State.Pickups
 	gm.State.PlusOneActive = update.State.PlusOneActive
 	gm.State.TpsTracker = update.State.TpsTracker
 
@@ -155,7 +311,15 @@ func Run() {
 	if err != nil {
 		panic(err)
 	}
-	mode, ipStr, err := mainMenu(gm)
+	status := ""
+	keyBindsPath, err := configPath("keybinds.json")
+	if err == nil {
+		gm.KeyBinds, err = game.LoadKeyBinds(keyBindsPath)
+	}
+	if err != nil {
+		status = "Using the default key binds, unable to load them: " + err.Error()
+	}
+	mode, target, err := mainMenu(gm, keyBindsPath, status)
 	if err != nil {
 		panic(err)
 	}
@@ -166,18 +330,86 @@ func Run() {
 			panic(err)
 		}
 	case "multiplayer":
-		if err := connect(gm, ipStr); err != nil {
+		if err := connect(gm, target); err != nil {
 			panic(err)
 		}
 		if err := gm.Start(); err != nil {
 			panic(err)
 		}
+	case "editor":
+		ed, err := game.NewEditor(target, gm.Theme, gm.Render)
+		if err != nil {
+			panic(err)
+		}
+		if err := ed.Run(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func runTournament() error {
+	entrants := []string{}
+	if args.Entrants != "" {
+		entrants = strings.Split(args.Entrants, ",")
+	}
+
+	tm, err := tournament.New(args.Rounds, args.MaxTicks, entrants)
+	if err != nil {
+		return err
+	}
+	if err := tm.Run(func(played, total int) { fmt.Printf("\r\033[0JMatch %v/%v", played, total) }); err != nil {
+		return err
 	}
+	fmt.Print("\r\033[0J")
+	return tm.Print(os.Stdout)
 }
 
 func main() {
-	if args.Server {
-		if err := server.NewServer(args.IP, args.Port, args.MaxClients).Run(); err != nil {
+	if args.Command == "tournament" {
+		if err := runTournament(); err != nil {
+			panic(err)
+		}
+	} else if args.Command == "gym" {
+		if err := gym.Serve(os.Stdin, os.Stdout); err != nil {
+			panic(err)
+		}
+	} else if args.Command != "" {
+		panic("unknown command: " + args.Command)
+	} else if args.Server {
+		sv := server.NewServer(args.IP, args.Port, args.MaxClients)
+		if args.Bots != "" {
+			botLevel, err := game.ParseBotLevel(args.Bots)
+			if err != nil {
+				panic(err)
+			}
+			sv.FillBots, sv.BotLevel = true, botLevel
+		}
+		if !slices.Contains(game.WallModes, args.Walls) {
+			panic("unknown wall mode: " + args.Walls)
+		}
+		if !slices.Contains(game.BodyModes, args.DeadBodies) {
+			panic("unknown dead body mode: " + args.DeadBodies)
+		}
+		if !slices.Contains(game.SpeedCurves, args.SpeedCurve) {
+			panic("unknown speed curve: " + args.SpeedCurve)
+		}
+		if args.Arena != "" && args.Arena != game.ArenaNone {
+			if _, err := game.ResolveArena(args.Arena); err != nil {
+				panic(err)
+			}
+		}
+		sv.Rules = server.Rules{
+			Arena:       args.Arena,
+			WallMode:    args.Walls,
+			ShorterDies: args.ShorterDies,
+			DeadBodies:  args.DeadBodies,
+			DecayDelay:  args.DecayDelay,
+			Pickups:     args.Pickups,
+			SpeedCurve:  args.SpeedCurve,
+			SpeedStep:   args.SpeedStep,
+			SpeedCap:    args.SpeedCap,
+		}
+		if err := sv.Run(); err != nil {
 			panic(err)
 		}
 		fmt.Println()
@@ -0,0 +1,150 @@
+package screen
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Amount of colors the terminal can show.
+	ColorDepth int
+)
+
+const (
+	Depth16 ColorDepth = iota
+	Depth256
+	DepthTrue
+)
+
+var (
+	ErrColor = errors.New("color should be a terminal color name or #rrggbb")
+
+	// Names of the 16 terminal colors in the order of their codes.
+	ColorNames = []string{
+		"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+		"bright-black", "bright-red", "bright-green", "bright-yellow", "bright-blue", "bright-magenta", "bright-cyan", "bright-white",
+	}
+
+	// Usual values of the 16 terminal colors, used to find the closest one for `#rrggbb` colors.
+	colorValues = [][3]int{
+		{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+		{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+	}
+)
+
+// Detect the color depth of the terminal from `COLORTERM` and `TERM`.
+func DetectColorDepth() ColorDepth {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return DepthTrue
+	}
+
+	term := os.Getenv("TERM")
+	if strings.HasSuffix(term, "-direct") {
+		return DepthTrue
+	}
+	if strings.Contains(term, "256color") {
+		return Depth256
+	}
+	return Depth16
+}
+
+// Get the escape code setting the foreground to `color`, empty colors give an empty code.
+//
+// Colors are one of `ColorNames`, which always use the palette of the terminal, or `#rrggbb` reduced to `depth`.
+func Color(color string, depth ColorDepth) (string, error) {
+	return colorCode(color, depth, 30)
+}
+
+// Get the escape code setting the background to `color`, see `Color`.
+func Background(color string, depth ColorDepth) (string, error) {
+	return colorCode(color, depth, 40)
+}
+
+func colorCode(color string, depth ColorDepth, base int) (string, error) {
+	if color == "" {
+		return "", nil
+	}
+
+	for i, name := range ColorNames {
+		if strings.EqualFold(color, name) {
+			return ansiCode(i, base), nil
+		}
+	}
+
+	rgb, err := parseHex(color)
+	if err != nil {
+		return "", err
+	}
+	switch depth {
+	case DepthTrue:
+		return fmt.Sprintf("\033[%d;2;%d;%d;%dm", base+8, rgb[0], rgb[1], rgb[2]), nil
+	case Depth256:
+		return fmt.Sprintf("\033[%d;5;%dm", base+8, closest256(rgb)), nil
+	}
+	return ansiCode(closest(rgb, colorValues), base), nil
+}
+
+// Get the code of the `i`th of the 16 terminal colors, bright colors use the aixterm codes.
+func ansiCode(i, base int) string {
+	if i >= 8 {
+		return "\033[" + strconv.Itoa(base+60+i-8) + "m"
+	}
+	return "\033[" + strconv.Itoa(base+i) + "m"
+}
+
+func parseHex(color string) ([3]int, error) {
+	if len(color) != 7 || color[0] != '#' {
+		return [3]int{}, fmt.Errorf("%w: %v", ErrColor, color)
+	}
+	val, err := strconv.ParseUint(color[1:], 16, 32)
+	if err != nil {
+		return [3]int{}, fmt.Errorf("%w: %v", ErrColor, color)
+	}
+	return [3]int{int(val >> 16 & 0xff), int(val >> 8 & 0xff), int(val & 0xff)}, nil
+}
+
+// Get the index of the 256 color palette closest to `rgb`, from the 6x6x6 color cube or the gray ramp.
+func closest256(rgb [3]int) int {
+	levels := []int{0, 95, 135, 175, 215, 255}
+	cube, idx := [3]int{}, 16
+	for i, v := range rgb {
+		level := 0
+		for l, lv := range levels {
+			if (v-lv)*(v-lv) < (v-levels[level])*(v-levels[level]) {
+				level = l
+			}
+		}
+		cube[i] = levels[level]
+		idx += level * []int{36, 6, 1}[i]
+	}
+
+	gray := min(23, max(0, ((rgb[0]+rgb[1]+rgb[2])/3-3)/10))
+	grayVal := 8 + gray*10
+	if distance(rgb, [3]int{grayVal, grayVal, grayVal}) < distance(rgb, cube) {
+		return 232 + gray
+	}
+	return idx
+}
+
+// Get the index of the color in `colors` closest to `rgb`.
+func closest(rgb [3]int, colors [][3]int) int {
+	best, bestDist := 0, -1
+	for i, c := range colors {
+		if dist := distance(rgb, c); bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+func distance(a, b [3]int) int {
+	dist := 0
+	for i := range a {
+		dist += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return dist
+}
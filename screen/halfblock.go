@@ -0,0 +1,59 @@
+package screen
+
+// Get the lines of a `w` by `h` view drawn with half blocks, the top cell of every pair of rows is the foreground of `▀` and the bottom cell its background.
+func (f *Screen) halfBlockLines(w, h int) [][]byte {
+	colors := make([][]string, h+h%2)
+	for y := range colors {
+		colors[y] = make([]string, w)
+		if y >= h {
+			continue
+		}
+		for x := range w {
+			colors[y][x] = f.Palette[f.composite(f.ViewX+x, f.ViewY+y, x, y)]
+		}
+	}
+	if f.Minimap {
+		f.drawMinimap(w, h, func(x, y int, val uint8, ranked bool) {
+			color := f.MinimapEmptyColor
+			if ranked {
+				color = f.Palette[val]
+			}
+			colors[y][x] = color
+		})
+	}
+
+	codes := map[string][2]string{}
+	code := func(color string) (string, string) {
+		if _, ok := codes[color]; !ok {
+			fg, _ := Color(color, f.ColorDepth)
+			bg, _ := Background(color, f.ColorDepth)
+			codes[color] = [2]string{fg, bg}
+		}
+		return codes[color][0], codes[color][1]
+	}
+
+	lines := [][]byte{}
+	for y := 0; y < len(colors); y += 2 {
+		line := []byte{}
+		for x := range w {
+			top, bottom := colors[y][x], colors[y+1][x]
+			topFg, _ := code(top)
+			bottomFg, bottomBg := code(bottom)
+
+			switch {
+			case top == "" && bottom == "":
+				line = append(line, ' ')
+				continue
+			case top == bottom:
+				line = append(line, topFg+"█"...)
+			case top == "":
+				line = append(line, bottomFg+"▄"...)
+			default:
+				line = append(line, topFg+bottomBg+"▀"...)
+			}
+			line = append(line, "\033[0m"...)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,122 @@
+package screen
+
+import (
+	"slices"
+	"unicode"
+)
+
+type (
+	// Grid of objects drawn over or under the board, cells holding 0 are transparent.
+	//
+	// Layers never change the cells of the board, `Screen.GetColRow` only reads the board.
+	Layer struct {
+		Rows [][]uint8
+		// Layers with a higher z are drawn over layers with a lower z, the board sits between layers above and up to 0.
+		Z int
+		// Keep the layer in place in the view instead of scrolling along with the board.
+		Fixed bool
+	}
+)
+
+// Add a layer the size of the board at `z`, layers keep their cells when the board is resized.
+func (f *Screen) NewLayer(z int, fixed bool) *Layer {
+	layer := &Layer{Z: z, Fixed: fixed}
+	layer.resize(f.CurX+1, f.CurY+1)
+
+	f.Layers = append(f.Layers, layer)
+	slices.SortStableFunc(f.Layers, func(a, b *Layer) int { return a.Z - b.Z })
+	return layer
+}
+
+func (f *Screen) resizeLayers() {
+	for _, layer := range f.Layers {
+		layer.resize(f.CurX+1, f.CurY+1)
+	}
+}
+
+// Get the object drawn at board cell `x`, `y` shown at view cell `viewX`, `viewY`, the topmost cell holding an object wins.
+func (f *Screen) composite(x, y, viewX, viewY int) uint8 {
+	for i := len(f.Layers) - 1; i >= 0 && f.Layers[i].Z > 0; i-- {
+		if val := f.Layers[i].at(x, y, viewX, viewY); val != 0 {
+			return val
+		}
+	}
+	if val := f.Rows[y][x]; val != 0 {
+		return val
+	}
+	for i := len(f.Layers) - 1; i >= 0; i-- {
+		if f.Layers[i].Z > 0 {
+			continue
+		}
+		if val := f.Layers[i].at(x, y, viewX, viewY); val != 0 {
+			return val
+		}
+	}
+	return 0
+}
+
+func (l *Layer) resize(x, y int) {
+	rows := make([][]uint8, y)
+	for i := range rows {
+		rows[i] = make([]uint8, x)
+		if i < len(l.Rows) {
+			copy(rows[i], l.Rows[i])
+		}
+	}
+	l.Rows = rows
+}
+
+func (l *Layer) at(x, y, viewX, viewY int) uint8 {
+	if l.Fixed {
+		x, y = viewX, viewY
+	}
+	val, _ := l.GetColRow(x, y)
+	return val
+}
+
+func (l *Layer) SetColRow(x, y int, state uint8) error {
+	if y > len(l.Rows)-1 || y < 0 {
+		return ErrYOutOfBounds
+	}
+	if x > len(l.Rows[y])-1 || x < 0 {
+		return ErrXOutOfBounds
+	}
+
+	l.Rows[y][x] = state
+	return nil
+}
+
+func (l *Layer) GetColRow(x, y int) (uint8, error) {
+	if y > len(l.Rows)-1 || y < 0 {
+		return 0, ErrYOutOfBounds
+	}
+	if x > len(l.Rows[y])-1 || x < 0 {
+		return 0, ErrXOutOfBounds
+	}
+
+	return l.Rows[y][x], nil
+}
+
+func (l *Layer) Clear() {
+	for _, r := range l.Rows {
+		clear(r)
+	}
+}
+
+func (l *Layer) RenderString(str string, offsetX, offsetY int, state uint8) {
+	for _, r := range str {
+		cords, ok := CharMap[unicode.ToUpper(r)]
+		if !ok {
+			continue
+		}
+
+		l.RenderCords(cords, offsetX, offsetY, state)
+		offsetX += 6
+	}
+}
+
+func (l *Layer) RenderCords(cords [][2]int, offsetX, offsetY int, state uint8) {
+	for _, cord := range cords {
+		_ = l.SetColRow(cord[0]+offsetX, cord[1]+offsetY, state)
+	}
+}
@@ -0,0 +1,55 @@
+package screen
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Watch the terminal for size changes until `stop` is called, a value is sent on `resizes` after every change.
+//
+// The view keeps the size of the last `UpdateSize` from now on instead of asking the terminal on every draw.
+func (f *Screen) WatchResize() (resizes <-chan struct{}, stop func()) {
+	f.UpdateSize()
+
+	ch, done := make(chan struct{}, 1), make(chan struct{})
+	go watchResize(ch, done)
+	return ch, func() { close(done) }
+}
+
+// Store the current size of the terminal as the size of the view, screens that never stored a size ask the terminal on every draw.
+func (f *Screen) UpdateSize() {
+	x, y, err := term.GetSize(int(os.Stdin.Fd()))
+	if err != nil {
+		return
+	}
+	f.size.Store(&[2]int{x, y})
+}
+
+func (f *Screen) getSize() (int, int, error) {
+	if size := f.size.Load(); size != nil {
+		return size[0], size[1], nil
+	}
+	return term.GetSize(int(os.Stdin.Fd()))
+}
+
+// Get the terminal columns and rows left of and above the view, centering the view in the terminal while `Letterbox` is set.
+func (f *Screen) Offset() (int, int) {
+	if !f.Letterbox {
+		return 0, 0
+	}
+	x, y, err := f.getSize()
+	if err != nil {
+		return 0, 0
+	}
+	w, h := f.TermSize()
+	return max(0, (x-w)/2), max(0, (y-1-h)/2)
+}
+
+// Send on `resizes` without blocking, a pending resize already covers this one.
+func notifyResize(resizes chan<- struct{}) {
+	select {
+	case resizes <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !unix
+
+package screen
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Terminals without `SIGWINCH` are checked for size changes a few times per second.
+func watchResize(resizes chan<- struct{}, done <-chan struct{}) {
+	x, y, _ := term.GetSize(int(os.Stdin.Fd()))
+	ticker := time.NewTicker(time.Millisecond * 250)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if newX, newY, err := term.GetSize(int(os.Stdin.Fd())); err == nil && (newX != x || newY != y) {
+				x, y = newX, newY
+				notifyResize(resizes)
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package screen
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func watchResize(resizes chan<- struct{}, done <-chan struct{}) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGWINCH)
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-sigs:
+			notifyResize(resizes)
+		}
+	}
+}
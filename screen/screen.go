@@ -5,20 +5,45 @@ import (
 	"errors"
 	"io"
 	"os"
-	"slices"
-	"unicode"
+	"strconv"
+	"sync/atomic"
 
 	"golang.org/x/term"
 )
 
 type (
 	Screen struct {
+		// Board drawn by the screen, set with `Show` and only ever read by the screen.
 		Rows                   [][]uint8
 		CurX, CurY, MaxX, MaxY int
-		ForceMax               bool
-		CharMap                map[uint8][]byte
-		Terminal               *term.Terminal
-		OnResizeCallback       func(f *Screen)
+		// Top left cell of the part of the board in view, boards larger than the terminal only show this part.
+		ViewX, ViewY int
+		ForceMax     bool
+		// Draw a reduced copy of the whole board over the bottom right corner of the view.
+		Minimap bool
+		// Rank of objects when a block of cells is reduced to one minimap cell, the highest ranked object is drawn.
+		//
+		// Defaults to ranking every object above empty cells.
+		MinimapRank func(val uint8) int
+		// Glyph of minimap cells without any ranked object.
+		MinimapEmpty []byte
+		// Draw every cell one column wide and pack two rows of cells into every terminal row with `▀`, using the colors of `Palette` instead of `CharMap`.
+		HalfBlock bool
+		// Colors of objects while drawing half blocks, see `Color`, objects without a color are drawn in the background of the terminal.
+		Palette map[uint8]string
+		// Color of minimap cells without any ranked object while drawing half blocks.
+		MinimapEmptyColor string
+		ColorDepth        ColorDepth
+		// Center the view in the terminal when the board is smaller than the terminal, see `Offset`.
+		Letterbox bool
+		// Layers drawn over and under the board, sorted by z, see `NewLayer`.
+		Layers   []*Layer
+		CharMap  map[uint8][]byte
+		Terminal *term.Terminal
+		// Size of the terminal stored by `UpdateSize`.
+		size atomic.Pointer[[2]int]
+		// Clear the terminal before the next draw, set by `ClearNext`.
+		clear atomic.Bool
 	}
 )
 
@@ -109,16 +134,14 @@ var (
 )
 
 func NewScreen(maxX, maxY int, forceMax bool, charMap map[uint8][]byte) (*Screen, error) {
-	x, y, err := term.GetSize(int(os.Stdin.Fd()))
-	if err != nil {
-		return &Screen{}, err
-	}
-	x = min(int(x/2), maxX)
-	y = min(y-1, maxY)
-
-	if forceMax {
-		x = maxX
-		y = maxY
+	x, y := maxX, maxY
+	if !forceMax {
+		termX, termY, err := term.GetSize(int(os.Stdin.Fd()))
+		if err != nil {
+			return &Screen{}, err
+		}
+		x = min(int(termX/2), maxX)
+		y = min(termY-1, maxY)
 	}
 
 	rows := [][]uint8{}
@@ -135,179 +158,178 @@ func NewScreen(maxX, maxY int, forceMax bool, charMap map[uint8][]byte) (*Screen
 			io.Reader
 			io.Writer
 		}{os.Stdin, os.Stdout}, ""),
-		OnResizeCallback: func(f *Screen) {},
 	}, nil
 }
 
-func (f *Screen) SetRow(y int, state uint8) error {
+func (f *Screen) GetColRow(x, y int) (uint8, error) {
 	if y > len(f.Rows)-1 || y < 0 {
-		return ErrXOutOfBounds
+		return 0, ErrYOutOfBounds
 	}
-
-	r := []uint8{}
-	for i := 0; i <= f.CurX; i++ {
-		r = append(r, state)
+	if x > len(f.Rows[y])-1 || x < 0 {
+		return 0, ErrXOutOfBounds
 	}
 
-	f.Rows = slices.Replace(f.Rows, y, y+1, r)
-
-	return nil
+	return f.Rows[y][x], nil
 }
 
-func (f *Screen) SetCol(x int, state uint8) error {
-	if 0 > len(f.Rows)-1 {
-		return ErrNoRowsFound
-	}
-	if x > len(f.Rows[0])-1 || x < 0 {
-		return ErrXOutOfBounds
-	}
+// Draw `rows` as the board from now on, the screen only reads them while drawing and keeps their size on terminal resizes.
+func (f *Screen) Show(rows [][]uint8) {
+	resized := len(rows) != len(f.Rows) || len(rows) > 0 && len(f.Rows) > 0 && len(rows[0]) != len(f.Rows[0])
 
-	for i := range f.Rows {
-		f.Rows[i] = slices.Replace(f.Rows[i], x, x+1, state)
+	f.Rows = rows
+	f.CurY = len(rows) - 1
+	f.CurX = -1
+	if len(rows) > 0 {
+		f.CurX = len(rows[0]) - 1
 	}
+	f.MaxX, f.MaxY = f.CurX+1, f.CurY+1
+	f.ForceMax = true
 
-	return nil
+	if resized {
+		f.resizeLayers()
+	}
 }
 
-func (f *Screen) SetColRow(x, y int, state uint8) error {
-	if y > len(f.Rows)-1 || y < 0 {
-		return ErrYOutOfBounds
+// Get the amount of columns and rows of the board that fit in the terminal, the whole board fits when stdin is not a terminal.
+func (f *Screen) ViewSize() (int, int) {
+	x, y, err := f.getSize()
+	if err != nil {
+		return f.CurX + 1, f.CurY + 1
 	}
-	if x > len(f.Rows[y])-1 || x < 0 {
-		return ErrXOutOfBounds
+	if f.HalfBlock {
+		return max(1, min(x, f.CurX+1)), max(1, min((y-1)*2, f.CurY+1))
 	}
-
-	f.Rows[y] = slices.Replace(f.Rows[y], x, x+1, state)
-
-	return nil
+	return max(1, min(int(x/2), f.CurX+1)), max(1, min(y-1, f.CurY+1))
 }
 
-func (f *Screen) GetColRow(x, y int) (uint8, error) {
-	if y > len(f.Rows)-1 || y < 0 {
-		return 0, ErrYOutOfBounds
-	}
-	if x > len(f.Rows[y])-1 || x < 0 {
-		return 0, ErrXOutOfBounds
+// Get the amount of terminal columns and rows the view is drawn in.
+func (f *Screen) TermSize() (int, int) {
+	w, h := f.ViewSize()
+	if f.HalfBlock {
+		return w, (h + 1) / 2
 	}
+	return w * 2, h
+}
 
-	return f.Rows[y][x], nil
+// Move the view just enough to keep cell `x`, `y` at least a quarter of the view away from its edges.
+func (f *Screen) Follow(x, y int) {
+	w, h := f.ViewSize()
+	f.ViewX = min(max(f.ViewX, x+w/4+1-w), x-w/4)
+	f.ViewY = min(max(f.ViewY, y+h/4+1-h), y-h/4)
 }
 
-func (f *Screen) Clear() {
-	for i := 0; i <= f.CurY; i++ {
-		f.Rows[i] = make([]uint8, f.CurX+1)
-	}
+// Clear the whole terminal at the start of the next `Draw`, safe to call while another goroutine is drawing.
+func (f *Screen) ClearNext() {
+	f.clear.Store(true)
 }
 
-func (f *Screen) Reload() error {
-	x, y, err := term.GetSize(int(os.Stdin.Fd()))
-	if err != nil {
-		return err
-	}
+func (f *Screen) Draw() error {
+	w, h := f.ViewSize()
+	f.ViewX = min(max(0, f.ViewX), f.CurX+1-w)
+	f.ViewY = min(max(0, f.ViewY), f.CurY+1-h)
 
-	f.CurX, f.CurY = min(int(x/2)-1, f.MaxX), min(y-2, f.MaxY)
-	if f.ForceMax {
-		f.CurX, f.CurY = f.MaxX, f.MaxY
+	render := f.glyphLines
+	if f.HalfBlock {
+		render = f.halfBlockLines
 	}
+	lines := append(render(w, h), []byte{})
 
-	f.Rows = [][]uint8{}
-	for i := 0; i <= f.CurY; i++ {
-		f.Rows = append(f.Rows, make([]uint8, f.CurX+1))
+	offX, offY := f.Offset()
+	if offX > 0 {
+		for i := range lines {
+			lines[i] = append([]byte("\033["+strconv.Itoa(offX+1)+"G"), lines[i]...)
+		}
 	}
 
+	out := []byte("\033[" + strconv.Itoa(offY+1) + ";0H")
+	if f.clear.Swap(false) {
+		out = append([]byte("\033[2J"), out...)
+	}
+	if _, err := f.Terminal.Write(append(out, bytes.Join(lines, []byte("\r\n"))...)); err != nil {
+		return err
+	}
 	return nil
 }
 
-func (f *Screen) Draw() error {
-	if !f.ForceMax {
-		x, y, err := term.GetSize(int(os.Stdin.Fd()))
-		if err != nil {
-			return err
+// Get the lines of a `w` by `h` view drawn with the glyphs of `CharMap`.
+func (f *Screen) glyphLines(w, h int) [][]byte {
+	glyphs := make([][][]byte, h)
+	for y := range h {
+		glyphs[y] = make([][]byte, w)
+		for x := range w {
+			glyphs[y][x] = f.glyph(f.composite(f.ViewX+x, f.ViewY+y, x, y))
 		}
-
-		if f.CurX != min(int(x/2)-1, f.MaxX) || f.CurY != min(y-2, f.MaxY) {
-			f.CurX, f.CurY = min(int(x/2)-1, f.MaxX), min(y-2, f.MaxY)
-
-			f.Rows = [][]uint8{}
-			for i := 0; i <= f.CurY; i++ {
-				f.Rows = append(f.Rows, make([]uint8, f.CurX+1))
+	}
+	if f.Minimap {
+		f.drawMinimap(w, h, func(x, y int, val uint8, ranked bool) {
+			glyph := f.MinimapEmpty
+			if ranked || glyph == nil {
+				glyph = f.glyph(val)
 			}
-
-			f.OnResizeCallback(f)
-		}
+			glyphs[y][x] = glyph
+		})
 	}
 
 	lines := [][]byte{}
-	for _, r := range f.Rows {
-		line := []byte{}
-		for _, col := range r {
-			char, ok := f.CharMap[col]
-			if ok {
-				line = append(line, char...)
-				continue
-			}
-
-			char, ok = f.CharMap[0]
-			if ok {
-				line = append(line, char...)
-				continue
-			}
+	for _, r := range glyphs {
+		lines = append(lines, bytes.Join(r, nil))
+	}
+	return lines
+}
 
-			if col != 0 {
-				line = append(line, []byte("██")...)
-				continue
-			}
+// Get the glyph `col` is drawn with.
+func (f *Screen) glyph(col uint8) []byte {
+	char, ok := f.CharMap[col]
+	if ok {
+		return char
+	}
 
-			line = append(line, []byte("  ")...)
-		}
-		lines = append(lines, line)
+	char, ok = f.CharMap[0]
+	if ok {
+		return char
 	}
-	lines = append(lines, []byte{})
 
-	if _, err := f.Terminal.Write(append([]byte("\033[0;0H"), bytes.Join(lines, []byte("\r\n"))...)); err != nil {
-		return err
+	if col != 0 {
+		return []byte("██")
 	}
-	return nil
+	return []byte("  ")
 }
 
-func (f *Screen) RenderString(str string, offsetX, offsetY int, state uint8) {
-	for _, r := range str {
-		cords, ok := CharMap[unicode.ToUpper(r)]
-		if !ok {
-			continue
-		}
-
-		f.RenderCords(cords, offsetX, offsetY, state)
-		offsetX += 6
+// Draw the whole board reduced to at most a third of a `w` by `h` view over its bottom right corner.
+//
+// `set` is called for every minimap cell with the highest ranked object of its block, `ranked` is false when the block has no ranked object.
+func (f *Screen) drawMinimap(w, h int, set func(x, y int, val uint8, ranked bool)) {
+	if w < 6 || h < 6 {
+		return
 	}
-}
 
-func (f *Screen) RenderStringIf(str string, offsetX, offsetY int, state uint8, set func(uint8) bool) {
-	for _, r := range str {
-		cords, ok := CharMap[unicode.ToUpper(r)]
-		if !ok {
-			continue
-		}
+	scale := max(2, (f.CurX+w/3)/(w/3), (f.CurY+h/3)/(h/3))
+	mapW, mapH := (f.CurX+scale)/scale, (f.CurY+scale)/scale
+
+	for my := range mapH {
+		for mx := range mapW {
+			best, bestRank := uint8(0), 0
+			for y := my * scale; y < min((my+1)*scale, f.CurY+1); y++ {
+				for x := mx * scale; x < min((mx+1)*scale, f.CurX+1); x++ {
+					// Fixed layers are out of bounds at negative view cells and stay off the minimap.
+					val := f.composite(x, y, -1, -1)
+					if rank := f.minimapRank(val); rank > bestRank {
+						best, bestRank = val, rank
+					}
+				}
+			}
 
-		f.RenderCordsIf(cords, offsetX, offsetY, state, set)
-		offsetX += 6
+			set(w-mapW+mx, h-mapH+my, best, bestRank > 0)
+		}
 	}
 }
 
-func (f *Screen) RenderCords(cords [][2]int, offsetX, offsetY int, state uint8) {
-	for _, cord := range cords {
-		_ = f.SetColRow(cord[0]+offsetX, cord[1]+offsetY, state)
+func (f *Screen) minimapRank(val uint8) int {
+	if f.MinimapRank != nil {
+		return f.MinimapRank(val)
 	}
-}
-
-func (f *Screen) RenderCordsIf(cords [][2]int, offsetX, offsetY int, state uint8, set func(uint8) bool) {
-	for _, cord := range cords {
-		val, err := f.GetColRow(cord[0]+offsetX, cord[1]+offsetY)
-		if err != nil {
-			continue
-		}
-		if set(val) {
-			_ = f.SetColRow(cord[0]+offsetX, cord[1]+offsetY, state)
-		}
+	if val != 0 {
+		return 1
 	}
+	return 0
 }
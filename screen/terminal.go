@@ -0,0 +1,67 @@
+package screen
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+type (
+	// Terminal in raw mode showing the alternate screen buffer with a hidden cursor, see `EnterFullscreen`.
+	Terminal struct {
+		fd      int
+		state   *term.State
+		signals chan os.Signal
+		once    sync.Once
+	}
+)
+
+// Switch the terminal to raw mode, the alternate screen buffer and a hidden cursor until `Restore` is called.
+//
+// SIGINT and SIGTERM restore the terminal before exiting, panics only do when `RestoreOnPanic` is deferred by the panicking goroutine.
+func EnterFullscreen() (*Terminal, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Terminal{fd: fd, state: state, signals: make(chan os.Signal, 1)}
+	signal.Notify(t.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-t.signals
+		if !ok {
+			return
+		}
+		t.Restore()
+		if code, ok := sig.(syscall.Signal); ok {
+			os.Exit(128 + int(code))
+		}
+		os.Exit(1)
+	}()
+
+	fmt.Print("\033[?1049h\033[?25l\033[2J\033[H")
+	return t, nil
+}
+
+// Leave the alternate screen buffer with the cursor shown and the terminal mode from before `EnterFullscreen`, only the first call does anything.
+func (t *Terminal) Restore() {
+	t.once.Do(func() {
+		signal.Stop(t.signals)
+		close(t.signals)
+		fmt.Print("\033[0m\033[?25h\033[?1049l")
+		_ = term.Restore(t.fd, t.state)
+	})
+}
+
+// Restore the terminal before passing a panic on, has to be deferred directly.
+func (t *Terminal) RestoreOnPanic() {
+	if r := recover(); r != nil {
+		t.Restore()
+		panic(r)
+	}
+}
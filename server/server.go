@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math/rand/v2"
+	"maps"
 	"net"
 	"slices"
 	"sort"
@@ -23,16 +23,39 @@ type (
 		IP         string
 		Port       uint16
 		MaxClients int
+		FillBots   bool
+		BotLevel   game.BotLevel
+		Rules      Rules
 		Pools      []*Pool
 		Lgr        *logger.Logger
 	}
 
+	// Game rules every new pool is started with.
+	Rules struct {
+		// Built-in arena or map file, empty for an empty arena.
+		Arena       string
+		WallMode    string
+		ShorterDies bool
+		DeadBodies  string
+		DecayDelay  int
+		Pickups     bool
+		SpeedCurve  string
+		SpeedStep   int
+		SpeedCap    int
+	}
+
 	Pool struct {
 		Clients    map[string]*net.Conn
+		BotClients map[string]bool
 		Game       *game.Game
 		MaxClients int
+		FillBots   bool
+		BotLevel   game.BotLevel
 		Status     string
 		Lgr        *logger.Logger
+		// Tick and deadline of the latest observation sent to each bot client, answers for other ticks or after the deadline are dropped.
+		ticks     map[string]int
+		deadlines map[string]time.Time
 	}
 )
 
@@ -45,6 +68,7 @@ func NewServer(ip string, port uint16, maxClients int) *Server {
 		IP:         ip,
 		Port:       port,
 		MaxClients: maxClients,
+		Rules:      Rules{WallMode: game.WallsWrap, DeadBodies: game.BodiesStay, SpeedCurve: game.SpeedFixed},
 		Pools:      []*Pool{},
 		Lgr:        lgr,
 	}
@@ -111,7 +135,7 @@ func (sv *Server) Run() error {
 				return
 			}
 			msg = strings.ReplaceAll(msg, "\n", "")
-			if string(msg) != "Join" {
+			if string(msg) != "Join" && string(msg) != "Bot" {
 				sv.Lgr.Log("medium", "Rejected", con.RemoteAddr().String())
 				_, _ = con.Write([]byte("Rejected\n"))
 				_ = con.Close()
@@ -126,16 +150,17 @@ func (sv *Server) Run() error {
 			}
 
 			for _, pl := range sv.Pools {
-				if len(pl.Clients) >= sv.MaxClients || (pl.Status != "initialized" && pl.Status != "waiting" && pl.Status != "started") {
+				// Bots filling a started pool take up slots like players do.
+				if len(pl.Clients)+len(pl.Game.Bots) >= sv.MaxClients || (pl.Status != "initialized" && pl.Status != "waiting" && pl.Status != "started") {
 					continue
 				}
 
 				sv.Lgr.Log("medium", "Accepted", con.RemoteAddr().String())
-				pl.AddClient(&con)
+				pl.AddClient(&con, msg == "Bot")
 				return
 			}
 
-			pl, err := NewPool(sv.MaxClients, sv.Lgr)
+			pl, err := NewPool(sv.MaxClients, sv.FillBots, sv.BotLevel, sv.Rules, sv.Lgr)
 			if err != nil {
 				sv.Lgr.Log("high", "Error", err)
 				_ = con.Close()
@@ -143,13 +168,13 @@ func (sv *Server) Run() error {
 			}
 
 			sv.Lgr.Log("medium", "Accepted", con.RemoteAddr().String())
-			pl.AddClient(&con)
+			pl.AddClient(&con, msg == "Bot")
 			sv.Pools = append(sv.Pools, pl)
 		}()
 	}
 }
 
-func NewPool(maxClients int, lgr *logger.Logger) (*Pool, error) {
+func NewPool(maxClients int, fillBots bool, botLevel game.BotLevel, rules Rules, lgr *logger.Logger) (*Pool, error) {
 	gm, err := game.NewGame(true)
 	if err != nil {
 		return &Pool{}, err
@@ -158,12 +183,32 @@ func NewPool(maxClients int, lgr *logger.Logger) (*Pool, error) {
 	gm.Config.PeaSpawnDelay = max(1, 5-maxClients)
 	gm.Config.PeaSpawnLimit = 4 * maxClients
 	gm.Config.PeaStartCount = 2 * maxClients
+	gm.Config.WallMode = rules.WallMode
+	gm.Config.ShorterDies = rules.ShorterDies
+	gm.Config.DeadBodies, gm.Config.DecayDelay = rules.DeadBodies, rules.DecayDelay
+	gm.Config.Pickups = rules.Pickups
+	gm.Config.SpeedCurve, gm.Config.SpeedStep, gm.Config.SpeedCap = rules.SpeedCurve, rules.SpeedStep, rules.SpeedCap
+	gm.DrawBorder()
+
+	if rules.Arena != "" && rules.Arena != game.ArenaNone {
+		arena, err := game.ResolveArena(rules.Arena)
+		if err != nil {
+			return &Pool{}, err
+		}
+		gm.LoadArena(arena)
+	}
 
 	p := &Pool{
-		Clients: map[string]*net.Conn{},
-		Game:    gm,
-		Status:  "initialized",
-		Lgr:     lgr,
+		Clients:    map[string]*net.Conn{},
+		BotClients: map[string]bool{},
+		Game:       gm,
+		MaxClients: maxClients,
+		FillBots:   fillBots,
+		BotLevel:   botLevel,
+		Status:     "initialized",
+		Lgr:        lgr,
+		ticks:      map[string]int{},
+		deadlines:  map[string]time.Time{},
 	}
 
 	go p.start()
@@ -171,39 +216,23 @@ func NewPool(maxClients int, lgr *logger.Logger) (*Pool, error) {
 	return p, nil
 }
 
-func (pool *Pool) AddClient(con *net.Conn) {
+// Add a client to the pool, bot clients receive a `game.Observation` every time their snake moved instead of a `game.GameState`.
+func (pool *Pool) AddClient(con *net.Conn, isBot bool) {
 	id := (*con).RemoteAddr().String()
+	if isBot {
+		pool.BotClients[id] = true
+	}
+
 	if pool.Status == "initialized" || pool.Status == "waiting" {
 		pool.Clients[id] = con
 		go pool.clientHandler(pool.Clients[id])
 
 	} else if pool.Status == "started" {
-		cord := [2]int{rand.IntN(pool.Game.Screen.CurX-1) + 1, rand.IntN(pool.Game.Screen.CurY-1) + 1}
-		for i := 1; i <= 100; i++ {
-			if i == 100 {
-				pool.Lgr.Log("high", "Error", "No space left for new player")
-				_ = (*con).Close()
-				return
-			}
-
-			valid := true
-			for y := -2; y < 3; y++ {
-				for x := -2; x < 3; x++ {
-					val, _ := pool.Game.Screen.GetColRow(cord[0]+x, cord[1]+y)
-					if val != game.ObjEmpty && val != game.ObjPlusOne {
-						valid = false
-						break
-					}
-				}
-				if !valid {
-					break
-				}
-			}
-			if !valid {
-				cord = [2]int{rand.IntN(pool.Game.Screen.CurX-1) + 1, rand.IntN(pool.Game.Screen.CurY-1) + 1}
-				continue
-			}
-			break
+		cord, err := pool.Game.FreeSpawn()
+		if err != nil {
+			pool.Lgr.Log("high", "Error", err)
+			_ = (*con).Close()
+			return
 		}
 
 		pool.Clients[id] = con
@@ -213,15 +242,19 @@ func (pool *Pool) AddClient(con *net.Conn) {
 			Crd: cord,
 			Dir: "right", CurDir: "right",
 			TailCrds: [][2]int{},
+			Color:    pool.Game.NextColor(),
 		}
 
 		update := game.FirstUpdatePacket{
 			ClientId:  id,
 			StartTime: pool.Game.StartTime,
-			MaxX:      pool.Game.Screen.MaxX, MaxY: pool.Game.Screen.MaxY,
+			MaxX:      pool.Game.Board.CurX + 1, MaxY: pool.Game.Board.CurY + 1,
+			WallMode: pool.Game.Config.WallMode,
+			Arena:    pool.Game.Arena,
 			State: game.GameState{
 				Players:       pool.Game.State.Players,
 				PeaCrds:       pool.Game.State.PeaCrds,
+				Pickups:       pool.Game.State.Pickups,
 				PlusOneActive: pool.Game.State.PlusOneActive,
 				TpsTracker:    pool.Game.State.TpsTracker,
 			},
@@ -260,6 +293,7 @@ func (pool *Pool) DelClient(con *net.Conn) {
 		pool.Game.State.Players[id] = playerState
 	}
 	delete(pool.Clients, id)
+	delete(pool.BotClients, id)
 }
 
 func (pool *Pool) start() {
@@ -271,12 +305,16 @@ func (pool *Pool) start() {
 		pool.Status = "stopped"
 	}()
 
-	queEndTime := time.Now().Add(time.Minute)
+	queTime := time.Minute
+	if pool.FillBots {
+		queTime = time.Second * 5
+	}
+	queEndTime := time.Now().Add(queTime)
 	pool.Status = "waiting"
 
 	for pool.Status == "waiting" {
 		if len(pool.Clients) == 0 {
-			queEndTime = time.Now().Add(time.Minute)
+			queEndTime = time.Now().Add(queTime)
 		}
 
 		if len(pool.Clients) >= 2 || time.Now().After(queEndTime) {
@@ -298,26 +336,24 @@ func (pool *Pool) start() {
 
 	i := 0
 	for id, client := range pool.Clients {
-		startY := int(pool.Game.Screen.CurY / 2)
-		if i%2 == 0 {
-			startY += i
-		} else {
-			startY -= (i + 1)
-		}
-
 		pool.Game.State.Players[id] = game.Player{
-			Crd: [2]int{int(pool.Game.Screen.CurX / 2), startY},
+			Crd: pool.Game.StartCrd(i),
 			Dir: "right", CurDir: "right",
 			TailCrds: [][2]int{},
+			Color:    pool.Game.NextColor(),
 		}
+		_ = pool.Game.Board.SetColRow(pool.Game.State.Players[id].Crd[0], pool.Game.State.Players[id].Crd[1], game.ObjPlayer)
 
 		update := game.FirstUpdatePacket{
 			ClientId:  id,
 			StartTime: pool.Game.StartTime,
-			MaxX:      pool.Game.Screen.MaxX, MaxY: pool.Game.Screen.MaxY,
+			MaxX:      pool.Game.Board.CurX + 1, MaxY: pool.Game.Board.CurY + 1,
+			WallMode: pool.Game.Config.WallMode,
+			Arena:    pool.Game.Arena,
 			State: game.GameState{
 				Players:       pool.Game.State.Players,
 				PeaCrds:       pool.Game.State.PeaCrds,
+				Pickups:       pool.Game.State.Pickups,
 				PlusOneActive: pool.Game.State.PlusOneActive,
 				TpsTracker:    pool.Game.State.TpsTracker,
 			},
@@ -334,55 +370,72 @@ func (pool *Pool) start() {
 		i++
 	}
 
+	if pool.FillBots {
+		for range pool.MaxClients - len(pool.Clients) {
+			if _, err := pool.Game.AddBot(pool.BotLevel); err != nil {
+				pool.Lgr.Log("high", "Error", err)
+				break
+			}
+		}
+	}
+
 	for i := 0; i < pool.Game.Config.PeaStartCount; i++ {
 		pool.Game.SpawnPea()
 	}
 
 	pool.Status = "started"
 
-	updateFramePlayer := max(1, pool.Game.Config.TargetTPS/pool.Game.Config.PlayerSpeed)
 	updateFramePea := max(1, pool.Game.Config.PeaSpawnDelay*pool.Game.Config.TargetTPS)
 	updateFramePlusOne := max(1, pool.Game.Config.PlusOneDelay*pool.Game.Config.TargetTPS)
 
 	for i := 1; pool.Status == "started"; i++ {
 		now := time.Now()
-		doSend := false
 
-		if i%updateFramePlayer == 0 {
-			doSend = true
-			isOneAlive := false
-			for id := range pool.Clients {
-				if pool.Game.State.Players[id].IsGameOver {
-					continue
-				}
+		isOneAlive := false
+		for id := range pool.Clients {
+			if !pool.Game.State.Players[id].IsGameOver {
 				isOneAlive = true
-				pool.Game.UpdatePlayer(id)
-			}
-			if !isOneAlive {
-				break
 			}
 		}
+		if !isOneAlive {
+			break
+		}
+
+		// Every snake moves at its own speed, so the state is sent whenever any of them moved.
+		moved, ticked := pool.Game.UpdateFrame(append(slices.Collect(maps.Keys(pool.Clients)), slices.Collect(maps.Keys(pool.Game.Bots))...), i)
+		doSend := len(moved) > 0 || ticked
 
 		if pool.Game.State.PlusOneActive && i%updateFramePlusOne == 0 {
 			pool.Game.State.PlusOneActive = false
 		}
 
+		// Bot clients steer their next move, which is due after the moves per second of their own snake.
+		for _, id := range moved {
+			if !pool.BotClients[id] {
+				continue
+			}
+			deadline := time.Second / time.Duration(pool.Game.SpeedOf(id))
+			pool.ticks[id], pool.deadlines[id] = i, time.Now().Add(deadline)
+			data, err := json.Marshal(pool.Game.Observe(id, i, deadline))
+			if err != nil {
+				pool.Lgr.Log("high", "Error", err)
+				continue
+			}
+			if _, err := (*pool.Clients[id]).Write(append(data, '\n')); err != nil {
+				pool.DelClient(pool.Clients[id])
+			}
+		}
+
 		if i%updateFramePea == 0 {
 			doSend = true
-			pool.Game.State.PeaCrds = slices.DeleteFunc(pool.Game.State.PeaCrds, func(cord [2]int) bool {
-				val, err := pool.Game.Screen.GetColRow(cord[0], cord[1])
-				return err != nil || val != game.ObjPea
-			})
-
-			if len(pool.Game.State.PeaCrds) < pool.Game.Config.PeaSpawnLimit {
-				pool.Game.SpawnPea()
-			}
+			pool.Game.UpdatePeas()
 		}
 
 		if doSend {
 			update := game.GameState{
 				Players:       pool.Game.State.Players,
 				PeaCrds:       pool.Game.State.PeaCrds,
+				Pickups:       pool.Game.State.Pickups,
 				PlusOneActive: pool.Game.State.PlusOneActive,
 				TpsTracker:    pool.Game.State.TpsTracker,
 			}
@@ -391,7 +444,10 @@ func (pool *Pool) start() {
 			if err != nil {
 				pool.Lgr.Log("high", "Error", err)
 			} else {
-				for _, client := range pool.Clients {
+				for id, client := range pool.Clients {
+					if pool.BotClients[id] {
+						continue
+					}
 					_, err = (*client).Write(append(data, '\n'))
 					if err != nil {
 						pool.DelClient(client)
@@ -423,11 +479,20 @@ func (pool *Pool) clientHandler(con *net.Conn) {
 		}
 		msg = strings.ReplaceAll(msg, "\n", "")
 
-		if !slices.Contains([]string{"up", "right", "down", "left"}, msg) {
+		id := (*con).RemoteAddr().String()
+		if pool.BotClients[id] {
+			tickStr, dir, ok := strings.Cut(msg, " ")
+			tick, err := strconv.Atoi(tickStr)
+			if !ok || err != nil || tick != pool.ticks[id] || time.Now().After(pool.deadlines[id]) {
+				continue
+			}
+			msg = dir
+		}
+
+		if !slices.Contains(game.Dirs, msg) {
 			continue
 		}
 
-		id := (*con).RemoteAddr().String()
 		playerState := pool.Game.State.Players[id]
 		playerState.Dir = msg
 		pool.Game.State.Players[id] = playerState
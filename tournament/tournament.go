@@ -0,0 +1,265 @@
+// Package tournament plays matches between registered bots in headless games as fast as possible.
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"ASnake/game"
+)
+
+type (
+	// Decides the direction player `id` moves in this tick.
+	Bot func(gm *game.Game, id string, tick int) string
+
+	Result struct {
+		Name                string
+		Matches             int
+		Wins, Draws, Losses int
+		TotalLength         int
+		TotalSurvival       int
+		Elo                 float64
+	}
+
+	Tournament struct {
+		Rounds   int
+		MaxTicks int
+		Entrants []string
+		Results  map[string]*Result
+	}
+)
+
+const (
+	EloStart float64 = 1000
+	EloK     float64 = 32
+)
+
+var (
+	ErrBotUnknown     = errors.New("unknown bot")
+	ErrBotRegistered  = errors.New("bot is already registered")
+	ErrTooFewEntrants = errors.New("at least 2 entrants are required")
+
+	bots     = map[string]Bot{}
+	botNames = []string{}
+)
+
+func init() {
+	for i, name := range game.BotLevels {
+		level := game.BotLevel(i)
+		_ = Register(strings.ToLower(name), func(gm *game.Game, id string, tick int) string { return gm.BotDir(id, level) })
+	}
+}
+
+// Register a bot under `name` so it can enter tournaments.
+func Register(name string, bot Bot) error {
+	if _, ok := bots[name]; ok {
+		return ErrBotRegistered
+	}
+	bots[name] = bot
+	botNames = append(botNames, name)
+	return nil
+}
+
+// Get the names of all registered bots in order of registration.
+func Registered() []string {
+	return slices.Clone(botNames)
+}
+
+// Wrap a bot written against `game.Observation`, like the ones used with `client.RunBot`.
+func FromObservation(decide func(obs game.Observation) string) Bot {
+	return func(gm *game.Game, id string, tick int) string {
+		return decide(gm.Observe(id, tick, 0))
+	}
+}
+
+// Get a new tournament where every pair of `entrants` plays `rounds` matches, all registered bots enter if `entrants` is empty.
+//
+// Matches without a winner after `maxTicks` player ticks are decided by length.
+func New(rounds, maxTicks int, entrants []string) (*Tournament, error) {
+	if len(entrants) == 0 {
+		entrants = Registered()
+	}
+	if len(entrants) < 2 {
+		return &Tournament{}, ErrTooFewEntrants
+	}
+
+	results := map[string]*Result{}
+	for _, name := range entrants {
+		if _, ok := bots[name]; !ok {
+			return &Tournament{}, fmt.Errorf("%w: %v", ErrBotUnknown, name)
+		}
+		results[name] = &Result{Name: name, Elo: EloStart}
+	}
+
+	return &Tournament{
+		Rounds:   rounds,
+		MaxTicks: maxTicks,
+		Entrants: entrants,
+		Results:  results,
+	}, nil
+}
+
+// Play all rounds, `onMatch` is called after every match with the amount of matches played and the total.
+func (t *Tournament) Run(onMatch func(played, total int)) error {
+	total := t.Rounds * len(t.Entrants) * (len(t.Entrants) - 1) / 2
+	played := 0
+
+	for range t.Rounds {
+		for i, a := range t.Entrants {
+			for _, b := range t.Entrants[i+1:] {
+				// Alternate seats so neither bot always starts on the same row.
+				seats := []string{a, b}
+				if played%2 == 1 {
+					seats = []string{b, a}
+				}
+
+				lengths, survival, err := t.match(seats)
+				if err != nil {
+					return err
+				}
+				t.record(seats, lengths, survival)
+
+				played++
+				if onMatch != nil {
+					onMatch(played, total)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Play a single match in a headless game, returns the final length and survived ticks per seat.
+func (t *Tournament) match(seats []string) ([]int, []int, error) {
+	gm, err := game.NewGame(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	gm.Config.PeaSpawnLimit = 4 * len(seats)
+	gm.Config.PeaStartCount = 2 * len(seats)
+
+	ids := []string{}
+	for i := range seats {
+		id := "seat" + strconv.Itoa(i+1)
+		ids = append(ids, id)
+		gm.State.Players[id] = game.Player{
+			Crd: gm.StartCrd(i),
+			Dir: "right", CurDir: "right",
+			TailCrds: [][2]int{},
+		}
+		_ = gm.Board.SetColRow(gm.State.Players[id].Crd[0], gm.State.Players[id].Crd[1], game.ObjPlayer)
+	}
+
+	for i := 0; i < gm.Config.PeaStartCount; i++ {
+		gm.SpawnPea()
+	}
+
+	peaEvery := gm.PlayerMovesPerPea()
+
+	survival := make([]int, len(seats))
+	for tick := 1; tick <= t.MaxTicks; tick++ {
+		alive := 0
+		for i, id := range ids {
+			if gm.State.Players[id].IsGameOver {
+				continue
+			}
+			alive++
+			playerState := gm.State.Players[id]
+			playerState.Dir = bots[seats[i]](gm, id, tick)
+			gm.State.Players[id] = playerState
+		}
+		if alive <= 1 {
+			break
+		}
+
+		gm.UpdatePlayers(ids)
+		for i, id := range ids {
+			if !gm.State.Players[id].IsGameOver {
+				survival[i] = tick
+			}
+		}
+
+		if tick%peaEvery == 0 {
+			gm.UpdatePeas()
+		}
+	}
+
+	lengths := make([]int, len(seats))
+	for i, id := range ids {
+		lengths[i] = len(gm.State.Players[id].TailCrds) + 1
+	}
+	return lengths, survival, nil
+}
+
+// Record the outcome of a 2 seat match, the longest survivor wins and equal survival is decided by length.
+func (t *Tournament) record(seats []string, lengths, survival []int) {
+	a, b := t.Results[seats[0]], t.Results[seats[1]]
+
+	score := 0.5
+	if survival[0] > survival[1] || (survival[0] == survival[1] && lengths[0] > lengths[1]) {
+		score = 1
+		a.Wins++
+		b.Losses++
+	} else if survival[0] < survival[1] || (survival[0] == survival[1] && lengths[0] < lengths[1]) {
+		score = 0
+		a.Losses++
+		b.Wins++
+	} else {
+		a.Draws++
+		b.Draws++
+	}
+
+	expected := 1 / (1 + math.Pow(10, (b.Elo-a.Elo)/400))
+	a.Elo += EloK * (score - expected)
+	b.Elo -= EloK * (score - expected)
+
+	for i, res := range []*Result{a, b} {
+		res.Matches++
+		res.TotalLength += lengths[i]
+		res.TotalSurvival += survival[i]
+	}
+}
+
+// Get all results sorted by elo, highest first.
+func (t *Tournament) Standings() []*Result {
+	results := []*Result{}
+	for _, name := range t.Entrants {
+		results = append(results, t.Results[name])
+	}
+	slices.SortStableFunc(results, func(a, b *Result) int {
+		if a.Elo > b.Elo {
+			return -1
+		} else if a.Elo < b.Elo {
+			return 1
+		}
+		return 0
+	})
+	return results
+}
+
+// Write the standings as a table.
+func (t *Tournament) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', tabwriter.AlignRight)
+	if _, err := fmt.Fprintln(tw, "Bot\tMatches\tWins\tDraws\tLosses\tAvg Length\tAvg Survival\tElo\t"); err != nil {
+		return err
+	}
+
+	for _, res := range t.Standings() {
+		matches := max(1, res.Matches)
+		if _, err := fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%.1f\t%.1f\t%.0f\t\n",
+			res.Name, res.Matches, res.Wins, res.Draws, res.Losses,
+			float64(res.TotalLength)/float64(matches),
+			float64(res.TotalSurvival)/float64(matches),
+			res.Elo,
+		); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,42 @@
+package tournament
+
+import (
+	"testing"
+
+	"ASnake/game"
+)
+
+func TestShorterSurvivorWins(t *testing.T) {
+	// Eats peas placed in front of it to grow to length 3, then crashes into a wall placed in front of it.
+	_ = Register("test-crash", func(gm *game.Game, id string, tick int) string {
+		crd := gm.NextCrd(gm.State.Players[id].Crd, "right")
+		switch tick {
+		case 1, 2:
+			gm.State.PeaCrds = append(gm.State.PeaCrds, crd)
+			_ = gm.Board.SetColRow(crd[0], crd[1], game.ObjPea)
+		case 5:
+			_ = gm.Board.SetColRow(crd[0], crd[1], game.ObjWall)
+		}
+		return "right"
+	})
+	_ = Register("test-up", func(gm *game.Game, id string, tick int) string { return "up" })
+
+	tm, err := New(1, 20, []string{"test-crash", "test-up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.Run(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	crash, up := tm.Results["test-crash"], tm.Results["test-up"]
+	if crash.TotalLength <= up.TotalLength {
+		t.Fatalf("lengths %v and %v, want the crashing bot to be longer", crash.TotalLength, up.TotalLength)
+	}
+	if crash.TotalSurvival >= up.TotalSurvival {
+		t.Errorf("survival %v and %v, want the crashing bot to survive shorter", crash.TotalSurvival, up.TotalSurvival)
+	}
+	if up.Wins != 1 || crash.Losses != 1 {
+		t.Errorf("wins %v and losses %v, want the shorter survivor to win", up.Wins, crash.Losses)
+	}
+}